@@ -5,6 +5,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"unicode"
 )
 
 // The flagMap a special type that stores the names of flags and
@@ -13,7 +14,7 @@ type flagMap map[string]int
 
 // The argValue is a special type to save the value of the argument.
 type argValue struct {
-	order int    // order of the argument on the command line
+	order int    // index of the flag or argument on the command line
 	value string // value for the argument
 }
 
@@ -26,6 +27,19 @@ type argValue struct {
 // not maintaining the order that was in the command line.
 type argMap map[string][]argValue
 
+// The isFlag returns true if the item looks like a flag, i.e. it starts
+// with a dash. In the permissive mode, the lone dash (like stdin for many
+// tools) and the dash followed by a digit (like -5) are values, because
+// the flags aren't declared and such items can't be checked.
+func isFlag(item string, cfg *config) bool {
+	if !strings.HasPrefix(item, "-") {
+		return false
+	}
+
+	return !cfg.permissive ||
+		len(item) > 1 && (item[1] < '0' || item[1] > '9')
+}
+
 // The parse converts the args slice to an argMap type.
 //
 // The shortFlags is a map of short flags which is used for
@@ -40,12 +54,38 @@ type argMap map[string][]argValue
 // for the verbose flag? This slice stores all available long flags
 // declared in the data structure.
 func (am argMap) parse(args []string, flags map[string]int) error {
+	return am.parseWith(args, flags, &config{})
+}
+
+// The parseWith is the same as parse but uses the parsing settings
+// from the cfg. In the permissive mode, every flag is accepted: any
+// long flag, and every letter of the short flag group is a flag until
+// the first non-letter character, which starts the value.
+//
+// In the known mode, the unknown flags are kept as is under the restFlag
+// key. In the short flag group, the known letters are the flags until the
+// first unknown character: the non-letter character starts the value of
+// the last known flag (-p8080), and the letters are kept as the group of
+// unknown flags (-vx gives v flag and -x is kept).
+//
+// In the stop mode, the parsing of the flags stops at the first positional
+// argument (except the path to the application), all next arguments are
+// positional. The arguments after -- or after the stop point are kept
+// verbatim under the rawFlag key, if the flags contain it.
+func (am argMap) parseWith(
+	args []string,
+	flags map[string]int,
+	cfg *config,
+) error {
 	// Controls of parsing state of positional arguments.
 	posState := struct {
 		order  int  // real index of the positional argument
 		active bool // true if switch to parsing of positional arguments
 	}{}
 
+	// The index of the first raw argument, -1 if there are no such.
+	raw := -1
+
 	// Remove all keys from the map that could be there
 	// from the previous parsing. We can't use am = make(argMap) here.
 	for key := range am {
@@ -60,15 +100,16 @@ func (am argMap) parse(args []string, flags map[string]int) error {
 		item := args[i]
 
 		switch {
-		case item == "--":
+		case item == "--" && !posState.active:
 			// Toggles the parser to read positional arguments.
 			//
 			// Example:
 			//   ./app 5 -UGoloop --verbose false -d -- 10 15
 			//
 			// where 5, 10, 15 is positional arguments
-			// and 10 isn't a value for -d flag.
-			posState.active = true
+			// and 10 isn't a value for -d flag. The next -- is
+			// a positional argument, like in ./app -- log -- file.
+			posState.active, raw = true, i+1
 		case strings.HasPrefix(item, "--") && !posState.active:
 			// Long flag. The flag for a boolean value may have the no- prefix.
 			// The value can be specified after the = symbol or as a series of
@@ -80,9 +121,12 @@ func (am argMap) parse(args []string, flags map[string]int) error {
 			//   ./app --user=Goloop --verbose=false
 			//   ./app --user Goloop --verbose false
 			//   ./app --user Goloop
+			//   ./app --user=
 			//
-			// where --user == "Goloop" and --verbose == false.
+			// where --user == "Goloop" and --verbose == false, the --user=
+			// is an explicit empty value, it doesn't take the next item.
 			var flag, data string
+			var hasData bool
 
 			// Separate the flags from the value, like:
 			// Example: --user=Goloop or --user Goloop where is user is a flag
@@ -95,12 +139,14 @@ func (am argMap) parse(args []string, flags map[string]int) error {
 			flag = strings.TrimPrefix(item, "--")
 			if tmp := strings.SplitN(flag, "=", 2); len(tmp) == 2 {
 				flag, data = strings.ToLower(tmp[0]), tmp[1]
+				hasData = true
 			} else {
 				flag = strings.ToLower(flag)
 			}
 
 			// Detect reverse mode and flag availability.
-			switch _, ok := flags[flag]; {
+			_, ok := flags[flag]
+			switch ok = ok || cfg.permissive && flag != ""; {
 			case !ok && strings.HasPrefix(flag, "no-"):
 				// The flag is not available, but there is
 				// a possibility that it needs reverse mode.
@@ -108,29 +154,46 @@ func (am argMap) parse(args []string, flags map[string]int) error {
 				if _, ok := flags[fwn]; ok {
 					// The flag needs reverse mode but cannot be
 					// reverse mode at the same time as data exists.
-					if data == "" {
-						flag, data = fwn, "false"
+					if !hasData {
+						flag, data, hasData = fwn, "false", true
 						break
 					}
 				}
 				fallthrough
 			case !ok:
+				if cfg.known {
+					// The unknown flag is kept as is, with the next item
+					// as its value, like --timeout 7, if the value isn't
+					// set after the = symbol. The --no- flags are boolean.
+					am.addRest(i, item)
+					if !hasData && !strings.HasPrefix(flag, "no-") &&
+						i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+						i++ // be sure to move to the right by one position
+						am.addRest(i, args[i])
+					}
+					continue
+				}
+
 				return fmt.Errorf("invalid argument %s", item)
 			}
 
-			key, value := flag, "true"
-			if data != "" {
-				value = string(data)
-			} else if i+1 < len(args) {
+			// The order is the index of the flag, not of its value.
+			// In the stop mode, the boolean flag doesn't take the next
+			// item, it's the first positional argument, like exec for
+			// the --verbose exec ls.
+			key, value, order := flag, "true", i
+			if hasData {
+				value = data
+			} else if i+1 < len(args) && !(cfg.stop && cfg.bools[key]) {
 				// Try to take the value from the next item.
-				if tmp := args[i+1]; !strings.HasPrefix(tmp, "-") {
+				if tmp := args[i+1]; !isFlag(tmp, cfg) {
 					value = tmp
 					i++ // be sure to move to the right by one position
 				}
 			}
 
-			am[key] = append(am[key], argValue{i, value})
-		case strings.HasPrefix(item, "-") && !posState.active:
+			am[key] = append(am[key], argValue{order, value})
+		case isFlag(item, cfg) && !posState.active:
 			// Short flag. The short flags can be grouped. The value can be
 			// concatenated to the flag or as a series of subsequent entries
 			// in the list.
@@ -139,8 +202,11 @@ func (am argMap) parse(args []string, flags map[string]int) error {
 			//   ./app -dUGoloop -g"Hello, world"
 			//   ./app -d -UGoloop -g "Hello, world"
 			//   ./app -U Goloop -dg "Hello, world"
+			//   ./app -dU=Goloop -g="Hello, world"
+			//   ./app -U ''
 			//
-			// where -d == true, -U == "Goloop" and -g == "Hello, world"
+			// where -d == true, -U == "Goloop" and -g == "Hello, world",
+			// the empty next item or -U= is an explicit empty value for -U.
 			var group, data []rune
 
 			// Separate the flags from the value, like:
@@ -157,7 +223,9 @@ func (am argMap) parse(args []string, flags map[string]int) error {
 			group = []rune(strings.TrimPrefix(item, "-"))
 			for i, c := range group {
 				_, exists := unique[c]
-				if _, ok := flags[string(c)]; !ok || exists {
+				_, ok := flags[string(c)]
+				ok = ok || cfg.permissive && unicode.IsLetter(c)
+				if !ok || exists {
 					group, data = group[:i], group[i:]
 					break
 				}
@@ -165,27 +233,51 @@ func (am argMap) parse(args []string, flags map[string]int) error {
 			}
 
 			// If no flag is set, this is a command line error.
-			if len(group) == 0 {
+			// In the known mode, the group which starts with unknown flag
+			// is kept as is, the letters after the known boolean flags are
+			// kept as the group of unknown flags, like -x for -vx where v
+			// is known. The letters after the known flag with the value
+			// are its value, like Goloop for -UGoloop.
+			switch {
+			case len(group) == 0 && cfg.known:
+				am.addRest(i, item)
+				continue
+			case len(group) == 0:
 				return fmt.Errorf("invalid argument %s", item)
+			case len(data) != 0 && cfg.known && unicode.IsLetter(data[0]) &&
+				cfg.bools[string(group[len(group)-1])]:
+				am.addRest(i, "-"+string(data))
+				for _, flag := range group {
+					key := string(flag)
+					am[key] = append(am[key], argValue{i, "true"})
+				}
+				continue
 			}
 
+			// The order is the index of the flag, not of its value.
+			order := i
 			for j, flag := range group {
 				key, value := string(flag), "true"
 
 				if j == len(group)-1 {
 					// For last flag in flag list only.
-					if len(data) != 0 {
+					// The = sign separates the value from the flag,
+					// like -p=8080, if it isn't disabled.
+					if len(data) != 0 && data[0] == '=' && !cfg.noShortEq {
+						value = string(data[1:])
+					} else if len(data) != 0 {
 						value = strings.TrimLeft(string(data), " ")
-					} else if i+1 < len(args) {
+					} else if i+1 < len(args) &&
+						!(cfg.stop && cfg.bools[key]) {
 						// Try to take the value from the next item.
-						if tmp := args[i+1]; !strings.HasPrefix(tmp, "-") {
+						if tmp := args[i+1]; !isFlag(tmp, cfg) {
 							value = tmp
 							i++ // be sure to move to the right by one position
 						}
 					}
 				}
 
-				am[key] = append(am[key], argValue{i, value})
+				am[key] = append(am[key], argValue{order, value})
 			}
 		default:
 			// Value for the previous flag or positional arguments.
@@ -201,14 +293,78 @@ func (am argMap) parse(args []string, flags map[string]int) error {
 			//   ./app  5 10 -dUGoloop --verbose -- 15
 			//
 			// where 5, 10, 15 is positional arguments.
+			//
+			// In the stop mode, the first positional argument
+			// toggles the parser to read positional arguments.
+			if cfg.stop && !posState.active && i != 0 {
+				posState.active, raw = true, i
+			}
+
 			am[fmt.Sprint(posState.order)] = []argValue{{i, item}}
 			posState.order++
 		}
 	}
 
+	// Keep the arguments after -- or after the stop point as is,
+	// if there is the field for them.
+	for i := raw; flags[rawFlag] != 0 && i >= 0 && i < len(args); i++ {
+		am[rawFlag] = append(am[rawFlag], argValue{i, args[i]})
+	}
+
 	return nil
 }
 
+// The rawFlag is the key of the argMap for the arguments after -- or after
+// the stop point, and the opt tag value of the field for them.
+const rawFlag = "--"
+
+// The rawValues returns the arguments after -- or after the stop point
+// as they are on the command line.
+func (am argMap) rawValues() []string {
+	result := make([]string, 0, len(am[rawFlag]))
+	for _, item := range am[rawFlag] {
+		result = append(result, item.value)
+	}
+
+	return result
+}
+
+// The restFlag is the key of the argMap for the arguments which are kept
+// as is in the known mode, and the opt tag value of the field for them.
+const restFlag = "..."
+
+// The addRest adds the item with the i index on the command line
+// to the arguments which are kept as is.
+func (am argMap) addRest(i int, item string) {
+	am[restFlag] = append(am[restFlag], argValue{i, item})
+}
+
+// The restValues returns the arguments which aren't consumed by the flags:
+// the unknown flags and the positional arguments which don't have the
+// fields in the flags, in the order of the command line.
+func (am argMap) restValues(flags map[string]int) []string {
+	tmp := append([]argValue{}, am[restFlag]...)
+	if flags["[]"] == 0 {
+		for key, items := range am {
+			order, err := strconv.Atoi(key)
+			if err == nil && order != 0 && flags[key] == 0 {
+				tmp = append(tmp, items...)
+			}
+		}
+	}
+
+	sort.Slice(tmp, func(i, j int) bool {
+		return tmp[i].order < tmp[j].order
+	})
+
+	result := make([]string, 0, len(tmp))
+	for _, item := range tmp {
+		result = append(result, item.value)
+	}
+
+	return result
+}
+
 // The asFlat returns argMap as simple map[string][]string.
 func (am argMap) asFlat() map[string][]string {
 	result := make(map[string][]string, len(am))
@@ -275,6 +431,32 @@ func (am argMap) posValues() []string {
 	return result
 }
 
+// The exists returns true if the value for the specified flag by
+// long and/or short name is set on the command line.
+func (am argMap) exists(shortFlag, longFlag string) bool {
+	for _, key := range []string{shortFlag, longFlag} {
+		if len(am[key]) != 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// The flagOrders returns the sorted indexes on the command line
+// of the specified flag by long and/or short name.
+func (am argMap) flagOrders(shortFlag, longFlag string) []int {
+	var result []int
+	for _, key := range []string{shortFlag, longFlag} {
+		for _, item := range am[key] {
+			result = append(result, item.order)
+		}
+	}
+
+	sort.Ints(result)
+	return result
+}
+
 // The flagValue returns the value for the specified flag by
 // long and/or short name with true as second param.
 // Returns defValue with false as second param if the value
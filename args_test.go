@@ -62,6 +62,31 @@ func TestParseMultiflags(t *testing.T) {
 	}
 }
 
+// TestParseEmpty tests parse method with explicit empty values.
+func TestParseEmpty(t *testing.T) {
+	flags := map[string]int{"U": 1, "users": 1, "verbose": 1}
+	expected := map[string][]string{
+		"0": {"./app"}, "1": {"5"}, "U": {""}, "users": {""},
+		"verbose": {"true"},
+	}
+	tests := [][]string{
+		{"./app", "--users=", "5", "-U", "", "--verbose"},
+		{"./app", "-U", "", "--verbose", "--users=", "5"},
+		{"./app", "-U=", "--verbose", "--users=", "5"},
+	}
+
+	am := argMap{}
+	for _, test := range tests {
+		if err := am.parse(test, flags); err != nil {
+			t.Error(err)
+		}
+
+		if !reflect.DeepEqual(am.asFlat(), expected) {
+			t.Errorf("expected %v but %v", expected, am)
+		}
+	}
+}
+
 // TestParseErrors tests parse methods with errors.
 func TestParseErrors(t *testing.T) {
 	split := func(str string) []string { return strings.Split(str, ":") }
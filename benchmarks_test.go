@@ -182,3 +182,53 @@ func BenchmarkSetValue(b *testing.B) {
 		}
 	})
 }
+
+func BenchmarkGetFieldCastList(b *testing.B) {
+	var cfg complexArgs
+	rt := reflect.TypeOf(cfg)
+
+	b.Run("Cached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, benchResult = getFieldCastList(&cfg)
+		}
+	})
+
+	b.Run("Uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, benchResult = parseFieldCastList(rt)
+		}
+	})
+}
+
+func BenchmarkUnmarshalLazyHelp(b *testing.B) {
+	type lazyArgs struct {
+		Host    string        `opt:"H" alt:"host" def:"localhost" help:"host of the server"`
+		Port    int           `opt:"port" alt:"p" def:"8080" help:"port of the server"`
+		Debug   bool          `opt:"d" help:"debug mode"`
+		Verbose bool          `opt:"verbose" help:"enable verbose mode"`
+		Configs []string      `opt:"c" sep:","`
+		Doc     func() string `opt:"?"`
+		Pos     []int         `opt:"[]"`
+	}
+
+	args := []string{
+		"./app",
+		"-H", "127.0.0.1",
+		"--port=8080",
+		"-d",
+		"--verbose=false",
+		"-c", "config1.yaml,config2.yaml",
+		"10",
+		"20",
+		"30",
+	}
+
+	var cfg lazyArgs
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		errs := unmarshalOpt(&cfg, args)
+		if errs != nil {
+			b.Fatal(errs[0])
+		}
+	}
+}
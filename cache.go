@@ -0,0 +1,39 @@
+package opt
+
+import (
+	"reflect"
+	"sync"
+)
+
+// The structInfo is the type-level data of the structure:
+// the parsed fields without instances, the map of flags, the nested
+// structures and problems in the field definitions.
+type structInfo struct {
+	fields fieldCastList   // fields without instances
+	flags  map[string]int  // flags of the fields, read only
+	bools  map[string]bool // flags of the boolean fields, read only
+	nested []nestedStruct  // nested structures for the lifecycle hooks
+	err    error           // problems in the field definitions
+}
+
+// The structCache is a concurrency-safe cache of the structInfo
+// by reflect.Type of the structure.
+var structCache sync.Map // map[reflect.Type]*structInfo
+
+// The getStructInfo returns structInfo of the rt structure type.
+// The structure is parsed only once for each type.
+func getStructInfo(rt reflect.Type) *structInfo {
+	if info, ok := structCache.Load(rt); ok {
+		return info.(*structInfo)
+	}
+
+	fields, err := parseFieldCastList(rt)
+	info := &structInfo{fields: fields, flags: fields.flags(),
+		bools: fields.boolFlags(), err: err}
+	collectNested(rt, "", nil, nil, &info.nested)
+
+	// Concurrent calls can parse the same type, it's harmless,
+	// but all of them should use the same structInfo.
+	actual, _ := structCache.LoadOrStore(rt, info)
+	return actual.(*structInfo)
+}
@@ -0,0 +1,74 @@
+package opt
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// TestGetStructInfo tests getStructInfo function.
+func TestGetStructInfo(t *testing.T) {
+	type data struct {
+		Host string `opt:"host" def:"localhost"`
+		Port int    `opt:"p" def:"8080"`
+	}
+
+	rt := reflect.TypeOf(data{})
+	infos := make([]*structInfo, 8)
+
+	var wg sync.WaitGroup
+	for i := range infos {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			infos[i] = getStructInfo(rt)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, info := range infos {
+		if info != infos[0] {
+			t.Errorf("%d test, expected the same struct info", i)
+		}
+	}
+
+	if infos[0].err != nil {
+		t.Error(infos[0].err)
+	}
+
+	if e := map[string]int{"host": 1, "p": 1}; !reflect.DeepEqual(
+		e, infos[0].flags) {
+		t.Errorf("expected %v but %v", e, infos[0].flags)
+	}
+}
+
+// TestBind tests bind method of the fieldCastList.
+func TestBind(t *testing.T) {
+	type db struct {
+		Host string
+	}
+
+	type data struct {
+		Port int
+		DB   *db
+	}
+
+	one, two := data{}, data{}
+	test := []string{"./app", "--db-host", "a"}
+	if err := unmarshalOpt(&one, test); err != nil {
+		t.Error(err)
+	}
+
+	test = []string{"./app", "--port", "80"}
+	if err := unmarshalOpt(&two, test); err != nil {
+		t.Error(err)
+	}
+
+	if one.DB == nil || one.DB.Host != "a" || one.Port != 0 {
+		t.Errorf("incorrect first object: %v", one)
+	}
+
+	if two.DB == nil || two.DB.Host != "" || two.Port != 80 {
+		t.Errorf("incorrect second object: %v", two)
+	}
+}
@@ -1,10 +1,12 @@
 package opt
 
 import (
+	"encoding"
 	"fmt"
 	"math"
 	"net/url"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -17,15 +19,22 @@ import (
 // passed for a field that is not a slice or array; etc.
 //
 // Generates panic if the structure has fields of the wrong type, for example:
-// field with the "?" key is not a string; the field with the "[]" key is not
-// a slice or array; the object is not a pointer; for unsupported field types
-// like: chan, map, etc..
+// field with the "?" key is not a string or func() string; the field with
+// the "[]" key is not a slice or array; the object is not a pointer; for
+// unsupported field types like: chan, func, etc..
 //
 // unmarshalOpt method supports the following field's types: int, int8, int16,
 // int32, int64, uin, uint8, uin16, uint32, in64, float32, float64, string,
 // bool, url.URL and pointers, array or slice from thous types (i.e. *int, ...,
 // []int, ..., []bool, ..., [2]*url.URL, etc.).
-func unmarshalOpt(obj interface{}, args []string) []error {
+//
+// The opts customize the parsing, the args option is ignored.
+func unmarshalOpt(obj interface{}, args []string, opts ...Option) []error {
+	return decodeOpt(obj, args, newConfig(opts...))
+}
+
+// The decodeOpt is the same as unmarshalOpt but uses the ready config.
+func decodeOpt(obj interface{}, args []string, cfg *config) []error {
 	var errs []error
 
 	// Analyze the structure and return a list of molds
@@ -34,174 +43,433 @@ func unmarshalOpt(obj interface{}, args []string) []error {
 	// If it's returns an error - be critical!
 	// This is a problem with an incorrect structure and we need to
 	// stop the program until the developer fixes this error.
-	fcl, err := getFieldCastList(obj)
+	//
+	// The type-level data of the structure is cached,
+	// so the structure is analyzed only once for each type.
+	rt, rv, err := validateStruct(obj)
 	if err != nil {
+		panic(err)
+	}
+
+	info := getStructInfo(rt.Elem())
+	if info.err != nil {
 		// Convert this error to panic because it's a problem of
 		// structure's fields (it's developer's problem).
-		panic(err)
+		panic(info.err)
 	}
+	fcl := info.fields.bind(rv.Elem())
+
+	// The structure and its nested structures can implement the lifecycle
+	// hooks: BeforeParse, SetDefaults, AfterParse and Validate.
+	// The small list of the targets is allocated on the stack.
+	targets := appendHookTargets(make(hookTargets, 0, 8),
+		obj, rv.Elem(), info.nested)
+	errs = append(errs, targets.beforeParse()...)
+
+	// The built-in options are parsed together with the fields,
+	// and are displayed in the help, but are set into the config.
+	flags, docs := info.flags, fcl
+	cfg.bools = info.bools
+	if len(cfg.builtin) != 0 {
+		flags = make(map[string]int, len(info.flags)+len(cfg.builtin))
+		for flag, count := range info.flags {
+			flags[flag] = count
+		}
+
+		for flag, count := range cfg.builtin.flags() {
+			flags[flag] += count
+		}
 
-	// Parse options.
+		cfg.bools = cfg.builtin.boolFlags()
+		for flag := range info.bools {
+			cfg.bools[flag] = true
+		}
+
+		docs = append(fcl[:len(fcl):len(fcl)], cfg.builtin...)
+	}
+
+	// Expand the response files, like @path, before parsing.
+	if cfg.respFile != 0 {
+		if tmp, err := expandResponseFiles(args, cfg.respFile); err != nil {
+			errs = append(errs, err)
+		} else {
+			args = tmp
+		}
+	}
+
+	// Parse options. The unknown flags are kept as is in the known mode,
+	// it's enabled by the ParseKnown or by the field for them.
 	am := argMap{}
-	if err := am.parse(args, fcl.flags()); err != nil {
+	cfg.known = cfg.known || info.flags[restFlag] != 0
+	if err := am.parseWith(args, flags, cfg); err != nil {
 		errs = append(errs, err)
 	}
 
+	if cfg.known {
+		cfg.rest = am.restValues(flags)
+	}
+
 	// Insert values into the fields of the structure
 	// from the command line arguments.
 	//
+	// The fields are filled in two passes: the first pass sets the
+	// default values into the fields that aren't set on the command line,
+	// then the Defaulter can change them, the second pass sets the
+	// values from the command line.
+	//
 	// Important: Do not interrupt the cycle on the first error:
 	//  - if there is a field for reference information,
 	//    it should be processed in any case;
 	//  - need to collect all possible errors.
-	for _, fc := range fcl {
-		if fc.tagGroup.shortFlag == "?" {
-			// Generate help info.
-			// The field must be of the string type, see in
-			// the getFieldCastList function.
-			help := getHelp(fcl, am)
-			fc.item.Set(reflect.ValueOf(help))
-			continue
-		}
-
-		// Contains a couple of arguments like: U, users, U or/and users -
-		// where U and users is synonyms.
-		// arg := fmt.Sprintf(
-		// 	"%s tmp/and %s", 		// <- tmp/and is a temporary string
-		// 	fc.tagGroup.shortFlag,
-		// 	fc.tagGroup.longFlag,
-		// )
-		// arg = strings.Trim(arg, " or/and ")
-
-		arg := strings.ReplaceAll(
-			fmt.Sprintf("%s or/and %s",
-				fc.tagGroup.shortFlag,
-				fc.tagGroup.longFlag),
-			" or/and ",
-			"",
+	for pass := 0; pass < 2; pass++ {
+		if pass == 1 {
+			targets.setDefaults()
+		}
+
+		for _, fc := range docs {
+			f := fc.tagGroup.shortFlag
+			exists := f == "?" || f == "[]" || f == restFlag ||
+				f == rawFlag ||
+				am.exists(fc.tagGroup.shortFlag, fc.tagGroup.longFlag)
+			if exists != (pass == 1) {
+				continue
+			}
+
+			var err error
+			switch f {
+			case restFlag:
+				// The arguments which aren't consumed by the flags.
+				fc.item.Set(reflect.Zero(fc.item.Type()))
+				err = fc.setValues(cfg.rest, true, defFormat)
+			case rawFlag:
+				// The arguments after -- or after the stop point.
+				fc.item.Set(reflect.Zero(fc.item.Type()))
+				err = fc.setValues(am.rawValues(), true, defFormat)
+			default:
+				err = fc.fill(docs, am, cfg)
+			}
+
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	// The AfterParse and Validate hooks are called only for the
+	// correctly parsed structure, and not for the built-in options
+	// like --help, which don't require valid values.
+	if len(errs) != 0 || cfg.help || cfg.showVersion {
+		return errs
+	}
+
+	if errs = targets.afterParse(); len(errs) != 0 {
+		return errs
+	}
+
+	return targets.validate()
+}
+
+// The fill sets the value from the am into the field, or the default value
+// if the field isn't set on the command line. The docs is a list of the
+// fields for the help generation. In the strict mode of the cfg, the repeated
+// flag of the field which isn't a list causes an error (see the strict tag
+// too). The integers are parsed in the format of the field (see intFormat).
+func (fc *fieldCast) fill(docs fieldCastList, am argMap, cfg *config) error {
+	if fc.tagGroup.shortFlag == "?" {
+		// Generate help info.
+		// The field must be of the string type or func() string type,
+		// see in the getFieldCastList function. The function generates
+		// help info on demand only.
+		if fc.item.Kind() == reflect.Func {
+			fc.item.Set(reflect.ValueOf(getLazyHelp(docs)))
+			return nil
+		}
+
+		help := getHelp(docs, am)
+		fc.item.Set(reflect.ValueOf(help))
+		return nil
+	}
+
+	value, kind, ok := []string{}, fc.item.Kind(), false
+	switch f := fc.tagGroup.shortFlag; {
+	case f == "[]":
+		// Get positional arguments.
+		value = am.posValues()
+	default:
+		// Get the values of the argument.
+		// The default value is resolved only if it's needed, because
+		// it can call the default value provider.
+		def := ""
+		if !am.exists(fc.tagGroup.shortFlag, fc.tagGroup.longFlag) {
+			def = fc.tagGroup.defaultValue()
+		}
+
+		value, ok = am.flagValue(
+			fc.tagGroup.shortFlag,
+			fc.tagGroup.longFlag,
+			def,
+			fc.tagGroup.sepList,
 		)
 
-		value, kind, ok := []string{}, fc.item.Kind(), false
-		switch f := fc.tagGroup.shortFlag; {
-		case f == "[]":
-			// Get positional arguments.
-			value = am.posValues()
-		default:
-			// Get the values of the argument.
-			value, ok = am.flagValue(
-				fc.tagGroup.shortFlag,
-				fc.tagGroup.longFlag,
-				fc.tagGroup.defValue,
-				fc.tagGroup.sepList,
-			)
+		// The user in the command line tries to pass arguments as
+		// list to a field that doesn't have the slice or array type.
+		list := (kind == reflect.Array || kind == reflect.Slice ||
+			kind == reflect.Map) && !isTextUnmarshaler(fc.item.Type())
+		if len(value) > 1 {
+			if !list {
+				// In this situation, we need to take the
+				// last value in the list. In the strict mode,
+				// the repeated flag is an error.
+				orders := am.flagOrders(
+					fc.tagGroup.shortFlag,
+					fc.tagGroup.longFlag,
+				)
+				if (cfg.strict || fc.tagGroup.strict) && len(orders) > 1 {
+					return fmt.Errorf("flag %s given %d times (argv %s)",
+						fc.flagName(), len(orders), joinInts(orders))
+				}
 
-			// The user in the command line tries to pass arguments as
-			// list to a field that doesn't have the slice or array type.
-			if len(value) > 1 {
-				if kind != reflect.Array && kind != reflect.Slice {
-					// In this situation, we need to take the
-					// last value in the list.
-					//
-					// return fmt.Errorf("%s used more than once", arg)
-					value = []string{value[len(value)-1]}
+				value = []string{value[len(value)-1]}
+			}
+		}
+
+		// The explicit empty value, like --users= or -U '', resets
+		// the list: the values before it and the default value
+		// are dropped, and the field holds the values after it only.
+		if ok && list {
+			for i := len(value) - 1; i >= 0; i-- {
+				if value[i] == "" {
+					value = value[i+1:]
+					fc.resetList()
+					break
 				}
 			}
 		}
+	}
 
-		// Set values of the desired type.
-		switch kind {
-		case reflect.Array:
-			// If a separator is specified, the elements must be separated.
-			var result []string
-
-			// If the argMap.flagValue hasn't value it's returns
-			// []string{defValue} where defValue can be like "" -
-			// this is not valid for the list because if the command line
-			// argument has no data for the list this list must be empty!
-			if !ok && len(value) == 1 && value[0] == "" {
+	// Set values of the desired type.
+	return fc.setValues(value, ok, fc.intFormat(cfg.literals))
+}
+
+// The resetList sets the empty list into the field: the empty slice,
+// the empty map or the array of zero values.
+func (fc *fieldCast) resetList() {
+	switch t := fc.item.Type(); t.Kind() {
+	case reflect.Slice:
+		fc.item.Set(reflect.MakeSlice(t, 0, 0))
+	case reflect.Map:
+		fc.item.Set(reflect.MakeMap(t))
+	default:
+		fc.item.Set(reflect.Zero(t))
+	}
+}
+
+// The setValues sets the values into the field. The ok is false if
+// the value was not found on the command line and it's a default value.
+// The nf is the format of the integer values: base and units.
+func (fc *fieldCast) setValues(
+	value []string,
+	ok bool,
+	nf numFormat,
+) (err error) {
+	// The types which implement encoding.TextUnmarshaler, like Optional,
+	// are converted from one value, even if they are lists or maps.
+	// The zero value is set if the value isn't passed on the command line
+	// and there is no default value, so Optional isn't marked as set.
+	if isTextUnmarshaler(fc.item.Type()) {
+		if !ok && len(value) == 1 && value[0] == "" {
+			fc.item.Set(reflect.Zero(fc.item.Type()))
+			return nil
+		}
+
+		return setValue(*fc.item, value[len(value)-1])
+	}
+
+	// Set values of the desired type.
+	switch kind := fc.item.Kind(); kind {
+	case reflect.Array:
+		// If a separator is specified, the elements must be separated.
+		var result []string
+
+		// If the argMap.flagValue hasn't value it's returns
+		// []string{defValue} where defValue can be like "" -
+		// this is not valid for the list because if the command line
+		// argument has no data for the list this list must be empty!
+		if !ok && len(value) == 1 && value[0] == "" {
+			break
+		}
+
+		if result, err = fc.tagGroup.splitValues(value); err != nil {
+			break
+		}
+
+		if max := fc.item.Type().Len(); len(result) > max {
+			// Array overflow.
+			// -> "%d items overflow [%d]%v array", len(result), max, kind,
+			// kind := fs.item.Index(0).Kind()
+			return fmt.Errorf(
+				"maximum number of values for %s argument "+
+					"is %d but passed %d values",
+				fc.flagName(), max, len(result),
+			)
+		}
+
+		err = fc.setList(fc.item, result, nf)
+	case reflect.Slice:
+		// Be sure to set Len equal Cap and more than zero.
+		// The slice must have at least one element to determine
+		// the type of the one.
+		// If a separator is specified, the elements must be separated.
+		var result []string
+
+		// If the argMap.flagValue hasn't value it's returns
+		// []string{defValue} where defValue can be like "" -
+		// this is not valid for the list because if the command line
+		// argument has no data for the list this list must be empty!
+		if !ok && len(value) == 1 && value[0] == "" {
+			break
+		}
+
+		if result, err = fc.tagGroup.splitValues(value); err != nil {
+			break
+		}
+
+		// The values replace the content of the slice,
+		// or are appended to it (see list tag).
+		if len(result) != 0 {
+			size := len(result)
+			tmp := reflect.MakeSlice(fc.item.Type(), size, size)
+			if err = fc.setList(&tmp, result, nf); err != nil {
 				break
 			}
 
-			if sep := fc.tagGroup.sepList; sep != "" {
-				for _, item := range value {
-					tmp := strings.Split(item, sep)
-					result = append(result, tmp...)
-				}
-			} else {
-				result = value
+			if fc.tagGroup.appendTo {
+				tmp = reflect.AppendSlice(*fc.item, tmp)
 			}
 
-			if max := fc.item.Type().Len(); len(result) > max {
-				// Array overflow.
-				// -> "%d items overflow [%d]%v array", len(result), max, kind,
-				// kind := fs.item.Index(0).Kind()
-				return []error{fmt.Errorf(
-					"maximum number of values for %s argument "+
-						"is %d but passed %d values",
-					arg, max, len(result),
-				)}
+			if fc.tagGroup.unique {
+				tmp = uniqueItems(tmp)
 			}
 
-			err = setSequence(fc.item, result)
-		case reflect.Slice:
-			// Be sure to set Len equal Cap and more than zero.
-			// The slice must have at least one element to determine
-			// the type of the one.
-			// If a separator is specified, the elements must be separated.
-			var result []string
-
-			// If the argMap.flagValue hasn't value it's returns
-			// []string{defValue} where defValue can be like "" -
-			// this is not valid for the list because if the command line
-			// argument has no data for the list this list must be empty!
-			if !ok && len(value) == 1 && value[0] == "" {
-				break
+			if fc.tagGroup.sorted {
+				sortItems(tmp)
 			}
 
-			if sep := fc.tagGroup.sepList; sep != "" {
-				for _, item := range value {
-					tmp := strings.Split(item, sep)
-					result = append(result, tmp...)
-				}
-			} else {
-				result = value
-			}
+			fc.item.Set(tmp)
+		}
+	case reflect.Map:
+		// The map is filled from the key=value pairs which can be
+		// passed as repeated flags or/and as separated list.
+		if !ok && len(value) == 1 && value[0] == "" {
+			break
+		}
 
-			if len(result) != 0 {
-				size := len(result)
-				tmp := reflect.MakeSlice(fc.item.Type(), size, size)
-				err = setSequence(&tmp, result)
-				if err == nil {
-					fc.item.Set(reflect.AppendSlice(*fc.item, tmp))
-				}
+		var result []string
+		if result, err = fc.tagGroup.splitValues(value); err != nil {
+			break
+		}
+
+		err = setMap(fc.item, result, fc.tagGroup.keySep,
+			fc.tagGroup.dupKey, nf)
+	case reflect.Ptr:
+		// The pointer stays nil if the value isn't passed on the command
+		// line and there is no default value, so the field can show that
+		// the option isn't provided.
+		if !ok && len(value) == 1 && value[0] == "" {
+			fc.item.Set(reflect.Zero(fc.item.Type()))
+			break
+		}
+
+		if elem := fc.item.Type().Elem(); elem != reflect.TypeOf(url.URL{}) {
+			// If the pointer is not to a structure. The nil pointer is
+			// allocated, and is set only if the value is correct.
+			tmp := *fc.item
+			if tmp.IsNil() {
+				tmp = reflect.New(elem)
 			}
-		case reflect.Ptr:
-			if fc.item.Type().Elem().Kind() != reflect.Struct {
-				// If the pointer is not to a structure.
-				tmp := reflect.Indirect(*fc.item)
-				err = setValue(tmp, value[len(value)-1])
-			} else {
-				// If a pointer to a structure of the url.URL.
-				err = setValue(*fc.item, value[len(value)-1])
+
+			err = setValueFormat(tmp.Elem(), value[len(value)-1], nf)
+			if err == nil {
+				fc.item.Set(tmp)
 			}
-		case reflect.Struct:
-			// Structure of the url.URL.
-			err = setValue(*fc.item, value[len(value)-1])
-		default:
-			// Set any type.
+		} else {
+			// If a pointer to a structure of the url.URL.
 			err = setValue(*fc.item, value[len(value)-1])
 		}
+	case reflect.Struct:
+		// Structure of the url.URL.
+		err = setValue(*fc.item, value[len(value)-1])
+	default:
+		// Set any type.
+		err = setValueFormat(*fc.item, value[len(value)-1], nf)
+	}
+
+	return err
+}
 
+// The setList sets the items into the slice or array item. For the list
+// of lists, each item is divided into the values of the inner list by
+// the delimiter from the sep2 tag, like: a,b;c,d gives [[a b] [c d]]
+// if sep is ";" and sep2 is ",".
+func (fc *fieldCast) setList(
+	item *reflect.Value,
+	seq []string,
+	nf numFormat,
+) error {
+	if !isList(item.Type().Elem()) {
+		return setSequence(item, seq, nf)
+	}
+
+	if item.Len() != len(seq) {
+		return nil
+	}
+
+	// The inner values are trimmed and checked for emptiness
+	// by the rules of the split tag, but they can't be quoted.
+	tg := tagGroup{
+		sepList:   fc.tagGroup.sepInner,
+		trimItems: fc.tagGroup.trimItems,
+		noEmpty:   fc.tagGroup.noEmpty,
+	}
+
+	for i, value := range seq {
+		values, err := tg.splitValues([]string{value})
 		if err != nil {
-			errs = append(errs, err)
+			return err
+		}
+
+		elem := item.Index(i)
+		switch elem.Kind() {
+		case reflect.Array:
+			if max := elem.Len(); len(values) > max {
+				return fmt.Errorf(
+					"maximum number of values for %s argument item "+
+						"is %d but passed %d values",
+					fc.flagName(), max, len(values),
+				)
+			}
+		case reflect.Slice:
+			elem.Set(reflect.MakeSlice(elem.Type(), len(values),
+				len(values)))
+		}
+
+		for j, v := range values {
+			if err := setValueFormat(elem.Index(j), v, nf); err != nil {
+				return err
+			}
 		}
 	}
 
-	return errs
+	return nil
 }
 
-// The setSequence sets slice into item.
-func setSequence(item *reflect.Value, seq []string) (err error) {
+// The setSequence sets slice into item, the nf is the format
+// of the integer values.
+func setSequence(
+	item *reflect.Value,
+	seq []string,
+	nf numFormat,
+) (err error) {
 	// defer func() {
 	// 	// Catch the panic and return an exception as a value.
 	// 	if r := recover(); r != nil {
@@ -216,7 +484,7 @@ func setSequence(item *reflect.Value, seq []string) (err error) {
 	// Set values from sequence.
 	for i, value := range seq {
 		elem := item.Index(i)
-		err := setValue(elem, value)
+		err := setValueFormat(elem, value, nf)
 		if err != nil {
 			return err
 		}
@@ -225,8 +493,138 @@ func setSequence(item *reflect.Value, seq []string) (err error) {
 	return nil
 }
 
-// The setValue sets value into field.
-func setValue(item reflect.Value, value string) (err error) {
+// The itemKey returns the key of the list item for the comparison:
+// the value for the pointer, the item itself for other types.
+// The ok is false if the item isn't comparable.
+func itemKey(item reflect.Value) (key interface{}, ok bool) {
+	if item.Kind() == reflect.Ptr && !item.IsNil() {
+		item = item.Elem()
+	}
+
+	return item.Interface(), item.Type().Comparable()
+}
+
+// The uniqueItems returns the list without the repeated items, the first
+// entry of the item is kept. The items which aren't comparable are kept.
+func uniqueItems(list reflect.Value) reflect.Value {
+	seen := make(map[interface{}]bool, list.Len())
+	result := reflect.MakeSlice(list.Type(), 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		item := list.Index(i)
+		if key, ok := itemKey(item); ok {
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+
+		result = reflect.Append(result, item)
+	}
+
+	return result
+}
+
+// The sortItems sorts the items of the list in ascending order. Numbers,
+// strings and booleans are compared by value, other types by their text
+// representation, the nil pointers are the first.
+func sortItems(list reflect.Value) {
+	sort.SliceStable(list.Interface(), func(i, j int) bool {
+		a, b := list.Index(i), list.Index(j)
+		if a.Kind() == reflect.Ptr {
+			if a.IsNil() || b.IsNil() {
+				return a.IsNil() && !b.IsNil()
+			}
+			a, b = a.Elem(), b.Elem()
+		}
+
+		switch a.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+			reflect.Int64:
+			return a.Int() < b.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+			reflect.Uint64:
+			return a.Uint() < b.Uint()
+		case reflect.Float32, reflect.Float64:
+			return a.Float() < b.Float()
+		case reflect.String:
+			return a.String() < b.String()
+		case reflect.Bool:
+			return !a.Bool() && b.Bool()
+		}
+
+		return itemText(a) < itemText(b)
+	})
+}
+
+// The itemText returns the text representation of the list item.
+func itemText(item reflect.Value) string {
+	if item.CanAddr() {
+		if s, ok := item.Addr().Interface().(fmt.Stringer); ok {
+			return s.String()
+		}
+	}
+
+	return fmt.Sprint(item.Interface())
+}
+
+// The setMap sets the key/value pairs into map item. The keySep separates
+// the key from the value in the each pair, the dupKey is the policy for the
+// duplicate keys: dupKeyLast, dupKeyFirst or dupKeyError.
+func setMap(
+	item *reflect.Value,
+	seq []string,
+	keySep,
+	dupKey string,
+	nf numFormat,
+) error {
+	t := item.Type()
+	result := reflect.MakeMapWithSize(t, len(seq))
+
+	for _, pair := range seq {
+		tmp := strings.SplitN(pair, keySep, 2)
+		if len(tmp) != 2 {
+			return fmt.Errorf("'%s' is not a key%svalue pair", pair, keySep)
+		}
+
+		key := reflect.ValueOf(tmp[0]).Convert(t.Key())
+		if result.MapIndex(key).IsValid() {
+			switch dupKey {
+			case dupKeyFirst:
+				continue
+			case dupKeyError:
+				return fmt.Errorf("duplicate key '%s'", tmp[0])
+			}
+		}
+
+		elem := reflect.New(t.Elem()).Elem()
+		if err := setValueFormat(elem, tmp[1], nf); err != nil {
+			return err
+		}
+		result.SetMapIndex(key, elem)
+	}
+
+	item.Set(result)
+	return nil
+}
+
+// The setValue sets value into field, the integers are decimal,
+// except the octal os.FileMode (see typeBase).
+func setValue(item reflect.Value, value string) error {
+	nf := defFormat
+	if item.Kind() == reflect.Uint32 && item.Type() == fileModeType {
+		nf.base = 8
+	}
+
+	return setValueFormat(item, value, nf)
+}
+
+// The setValueFormat sets value into field, the nf is the format
+// of the integer values: base (see strToIntKind) and units.
+func setValueFormat(
+	item reflect.Value,
+	value string,
+	nf numFormat,
+) (err error) {
 	defer func() {
 		// Catch the panic and return an exception as a value.
 		if r := recover(); r != nil {
@@ -234,19 +632,40 @@ func setValue(item reflect.Value, value string) (err error) {
 		}
 	}()
 
+	// The types which implement encoding.TextUnmarshaler,
+	// like Optional, convert the value themselves.
+	if item.CanAddr() && isTextUnmarshaler(item.Type()) {
+		u := item.Addr().Interface().(encoding.TextUnmarshaler)
+		return u.UnmarshalText([]byte(value))
+	}
+
 	kind := item.Kind()
 
 	switch kind {
 	case reflect.Int, reflect.Int8, reflect.Int16,
 		reflect.Int32, reflect.Int64:
-		r, err := strToIntKind(value, kind)
+		if nf.unit != "" {
+			if value, err = unitsToDec(value, nf.unit); err != nil {
+				return err
+			}
+			nf.base = defBase
+		}
+
+		r, err := strToIntKind(value, kind, nf.base)
 		if err != nil {
 			return err
 		}
 		item.SetInt(r)
 	case reflect.Uint, reflect.Uint8, reflect.Uint16,
 		reflect.Uint32, reflect.Uint64:
-		r, err := strToUintKind(value, kind)
+		if nf.unit != "" {
+			if value, err = unitsToDec(value, nf.unit); err != nil {
+				return err
+			}
+			nf.base = defBase
+		}
+
+		r, err := strToUintKind(value, kind, nf.base)
 		if err != nil {
 			return err
 		}
@@ -300,18 +719,29 @@ func setValue(item reflect.Value, value string) (err error) {
 
 // The strToIntKind convert string to int64 type with checking for conversion
 // to intX type. Returns default value for int type if value is empty.
+// The base is from 2 to 36, or 0 for the Go literal syntax (0x, 0o, 0b
+// prefixes and underscores). In the base 10 and 0 the integer-valued
+// exponent like 1e6 is allowed too.
 //
 // P.s. The intX determined by reflect.Kind.
-func strToIntKind(value string, kind reflect.Kind) (r int64, err error) {
+func strToIntKind(
+	value string,
+	kind reflect.Kind,
+	base int,
+) (r int64, err error) {
 	// For empty string returns zero.
 	if len(value) == 0 {
 		return 0, nil
 	}
 
 	// Convert string to int64.
-	r, err = strconv.ParseInt(value, 10, 64)
+	r, err = strconv.ParseInt(trimBasePrefix(value, base), base, 64)
 	if err != nil {
-		return 0, fmt.Errorf("'%v' is incorrect value", value)
+		f, ok := expToFloat(value, base)
+		if !ok || f < math.MinInt64 || f >= math.MaxInt64 {
+			return 0, fmt.Errorf("'%v' is incorrect value", value)
+		}
+		r, err = int64(f), nil
 	}
 
 	switch kind {
@@ -348,16 +778,26 @@ func strToIntKind(value string, kind reflect.Kind) (r int64, err error) {
 
 // strToUintKind convert string to uint64 type with checking for conversion
 // to uintX type. Returns default value for uint type if value is empty.
+// The base is the same as for strToIntKind.
 //
 // P.s. The uintX determined by reflect.Kind.
-func strToUintKind(value string, kind reflect.Kind) (r uint64, err error) {
+func strToUintKind(
+	value string,
+	kind reflect.Kind,
+	base int,
+) (r uint64, err error) {
 	// For empty string returns zero.
 	if len(value) == 0 {
 		return 0, nil
 	}
 
 	// Convert string to uint64.
-	r, err = strconv.ParseUint(value, 10, 64)
+	r, err = strconv.ParseUint(trimBasePrefix(value, base), base, 64)
+	if f, ok := expToFloat(value, base); err != nil && ok &&
+		f >= 0 && f < math.MaxUint64 {
+		r, err = uint64(f), nil
+	}
+
 	if err != nil {
 		return 0, fmt.Errorf(
 			"'%v' has incorrect type, positive number expected",
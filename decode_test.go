@@ -403,12 +403,73 @@ func TestPtr(t *testing.T) {
 		t.Error(err)
 	}
 
-	// Not initialized pointer.
+	if age != 99 {
+		t.Errorf("expected 99 but %d", age)
+	}
+
+	// Not initialized pointer is allocated.
 	obj = data{}
 	test = split("./app:--age:99")
+	if err := unmarshalOpt(&obj, test); err != nil {
+		t.Error(err)
+	}
+
+	if obj.Age == nil || *obj.Age != 99 {
+		t.Errorf("expected 99 but %v", obj.Age)
+	}
+
+	// The pointer is nil if the flag and the default value are absent.
+	test = split("./app:--debug")
+	if err := unmarshalOpt(&obj, test); err != nil {
+		t.Error(err)
+	}
+
+	if obj.Age != nil {
+		t.Errorf("expected nil but %d", *obj.Age)
+	}
+
+	// The pointer isn't allocated for incorrect value.
+	test = split("./app:--age:old")
 	if err := unmarshalOpt(&obj, test); err == nil {
 		t.Error("expected error")
 	}
+
+	if obj.Age != nil {
+		t.Errorf("expected nil but %d", *obj.Age)
+	}
+}
+
+// TestPtrTriState tests the pointer fields with the default values.
+func TestPtrTriState(t *testing.T) {
+	type data struct {
+		Debug   *bool    `opt:"d" alt:"debug"`
+		Port    *int     `opt:"port" def:"80"`
+		Name    *string  `opt:"name"`
+		Site    *url.URL `opt:"site"`
+		Verbose *bool    `opt:"v"`
+	}
+
+	obj := data{}
+	test := []string{"./app", "-d", "--name", ""}
+	if err := unmarshalOpt(&obj, test); err != nil {
+		t.Error(err)
+	}
+
+	if obj.Debug == nil || !*obj.Debug {
+		t.Errorf("expected true but %v", obj.Debug)
+	}
+
+	if obj.Port == nil || *obj.Port != 80 {
+		t.Errorf("expected default 80 but %v", obj.Port)
+	}
+
+	if obj.Name == nil || *obj.Name != "" {
+		t.Errorf("expected empty string but %v", obj.Name)
+	}
+
+	if obj.Site != nil || obj.Verbose != nil {
+		t.Errorf("expected nil but %v, %v", obj.Site, obj.Verbose)
+	}
 }
 
 // TestStructPtr tests with pointer on struct.
@@ -585,7 +646,7 @@ func TestStrToIntKind(t *testing.T) {
 
 	// Test correct values.
 	for _, data := range tests {
-		r, err := strToIntKind(data.Value, data.Kind)
+		r, err := strToIntKind(data.Value, data.Kind, 10)
 		if data.Correct && err != nil {
 			t.Error(err)
 		} else if !data.Correct && err == nil {
@@ -640,7 +701,7 @@ func TestStrToUintKind(t *testing.T) {
 
 	// Test correct values.
 	for _, data := range tests {
-		r, err := strToUintKind(data.Value, data.Kind)
+		r, err := strToUintKind(data.Value, data.Kind, 10)
 		if data.Correct && err != nil {
 			t.Error(err)
 		} else if !data.Correct && err == nil {
@@ -696,3 +757,348 @@ func TestStrToFloatKind(t *testing.T) {
 		}
 	}
 }
+
+// TestMap tests unmarshalOpt with map fields.
+func TestMap(t *testing.T) {
+	type data struct {
+		Define map[string]string `opt:"D" sep:","`
+		Label  map[string]int    `opt:"label" kvsep:":" def:"a:1,b:2" sep:","`
+		First  map[string]bool   `opt:"f" dup:"first"`
+		Strict map[string]string `opt:"s" dup:"error"`
+	}
+
+	split := func(str string) []string { return strings.Split(str, ":") }
+
+	// Repeated flags and separated values.
+	obj := data{}
+	test := split("./app:-Da=1,b=2:-D:c=x=y:-Da=3")
+	if err := unmarshalOpt(&obj, test); err != nil {
+		t.Error(err)
+	}
+
+	exp := map[string]string{"a": "3", "b": "2", "c": "x=y"}
+	if !reflect.DeepEqual(exp, obj.Define) {
+		t.Errorf("expected %v but %v", exp, obj.Define)
+	}
+
+	// Default value.
+	if e := map[string]int{"a": 1, "b": 2}; !reflect.DeepEqual(e, obj.Label) {
+		t.Errorf("expected %v but %v", e, obj.Label)
+	}
+
+	if obj.First != nil || obj.Strict != nil {
+		t.Errorf("expected nil maps but %v and %v", obj.First, obj.Strict)
+	}
+
+	// Duplicate key policy.
+	obj = data{}
+	test = []string{"./app", "-fa=true", "-fa=false", "--label", "c:3"}
+	if err := unmarshalOpt(&obj, test); err != nil {
+		t.Error(err)
+	}
+
+	if e := map[string]bool{"a": true}; !reflect.DeepEqual(e, obj.First) {
+		t.Errorf("expected %v but %v", e, obj.First)
+	}
+
+	if e := map[string]int{"c": 3}; !reflect.DeepEqual(e, obj.Label) {
+		t.Errorf("expected %v but %v", e, obj.Label)
+	}
+
+	test = []string{"./app", "-sa=1", "-sa=2"}
+	if err := unmarshalOpt(&data{}, test); err == nil {
+		t.Error("expected an error for duplicate key")
+	}
+
+	// Incorrect values.
+	test = []string{"./app", "-Da"}
+	if err := unmarshalOpt(&data{}, test); err == nil {
+		t.Error("expected an error for value without key")
+	}
+
+	test = []string{"./app", "--label", "a:b"}
+	if err := unmarshalOpt(&data{}, test); err == nil {
+		t.Error("expected an error for incorrect value")
+	}
+}
+
+// TestNested tests unmarshalOpt with nested structures.
+func TestNested(t *testing.T) {
+	type db struct {
+		Host string `def:"localhost"`
+		Port int    `opt:"P" alt:"port" def:"5432"`
+	}
+
+	type common struct {
+		Debug bool `opt:"d"`
+	}
+
+	type replica struct {
+		Host string
+		Port int
+	}
+
+	type data struct {
+		common
+		DB      db
+		Replica *replica `prefix:"ro"`
+		Cache   struct {
+			Size int `def:"64"`
+		} `prefix:""`
+		Skip db `opt:"-"`
+	}
+
+	obj := data{}
+	test := []string{"./app", "-d", "--db-host", "db.local", "-P", "5433",
+		"--ro-host=replica.local", "--size", "128"}
+	if err := unmarshalOpt(&obj, test); err != nil {
+		t.Error(err)
+	}
+
+	if !obj.Debug {
+		t.Error("expected true for embedded field")
+	}
+
+	if e := (db{"db.local", 5433}); obj.DB != e {
+		t.Errorf("expected %v but %v", e, obj.DB)
+	}
+
+	if obj.Replica == nil {
+		t.Fatal("expected allocated pointer to the nested struct")
+	}
+
+	if e := (replica{"replica.local", 0}); *obj.Replica != e {
+		t.Errorf("expected %v but %v", e, *obj.Replica)
+	}
+
+	if obj.Cache.Size != 128 {
+		t.Errorf("expected 128 but %d", obj.Cache.Size)
+	}
+
+	if e := (db{}); obj.Skip != e {
+		t.Errorf("expected %v but %v", e, obj.Skip)
+	}
+}
+
+// TestStrict tests the strict mode for the repeated flags.
+func TestStrict(t *testing.T) {
+	type data struct {
+		Host  string   `opt:"h" alt:"host"`
+		Port  int      `opt:"p" alt:"port"`
+		Users []string `opt:"U"`
+		Debug bool     `opt:"d" strict:"true"`
+	}
+
+	tests := []struct {
+		args   []string
+		strict bool
+		err    string
+	}{
+		{
+			args: []string{"./app", "-p", "80", "-U", "a", "-U", "b"},
+		},
+		{
+			args: []string{"./app", "-h", "x", "-p", "80", "--port=8080"},
+		},
+		{
+			args:   []string{"./app", "-h", "x", "-p", "80", "--port=8080"},
+			strict: true,
+			err:    "flag --port given 2 times (argv 3, 5)",
+		},
+		{
+			args: []string{"./app", "-dh", "x", "-h", "y", "-d"},
+			err:  "flag -d given 2 times (argv 1, 5)",
+		},
+	}
+
+	for i, test := range tests {
+		var opts []Option
+		if test.strict {
+			opts = append(opts, WithStrict())
+		}
+
+		args := data{}
+		errs := unmarshalOpt(&args, test.args, opts...)
+		switch {
+		case test.err == "" && len(errs) != 0:
+			t.Errorf("%d test, unexpected error %v", i, errs)
+		case test.err != "" && len(errs) != 1:
+			t.Errorf("%d test, expected one error but %v", i, errs)
+		case test.err != "" && errs[0].Error() != test.err:
+			t.Errorf("%d test, expected %q but %q", i, test.err, errs[0])
+		}
+	}
+
+	wrong := struct {
+		Port int `strict:"yes"`
+	}{}
+
+	if err := Check(&wrong); err == nil {
+		t.Error("expected an error for incorrect strict tag")
+	}
+}
+
+// TestExplicitEmpty tests explicit empty values like --name= and -n "".
+func TestExplicitEmpty(t *testing.T) {
+	type data struct {
+		Name  string            `opt:"n" alt:"name" def:"Goloop"`
+		Port  int               `opt:"p" alt:"port" def:"80"`
+		Users []string          `opt:"U" alt:"users" def:"Jan,Bob" sep:","`
+		Point [2]int            `opt:"point" def:"1,2" sep:","`
+		Env   map[string]string `opt:"e" def:"A=1"`
+		Args  []string          `opt:"[]"`
+	}
+
+	tests := []struct {
+		args     []string
+		expected data
+	}{
+		{
+			args: []string{"./app"},
+			expected: data{
+				Name: "Goloop", Port: 80, Users: []string{"Jan", "Bob"},
+				Point: [2]int{1, 2}, Env: map[string]string{"A": "1"},
+			},
+		},
+		{
+			args: []string{"./app", "--name=", "--port=", "--users=",
+				"--point=", "-e", "", "x"},
+			expected: data{
+				Users: []string{}, Env: map[string]string{},
+				Args: []string{"x"},
+			},
+		},
+		{
+			args: []string{"./app", "-n", "", "-U", "", "-U", "Smit"},
+			expected: data{
+				Port: 80, Users: []string{"Smit"}, Point: [2]int{1, 2},
+				Env: map[string]string{"A": "1"},
+			},
+		},
+		{
+			args: []string{"./app", "-UJan", "--users=", "-U", "Bob,Smit"},
+			expected: data{
+				Name: "Goloop", Port: 80, Users: []string{"Bob", "Smit"},
+				Point: [2]int{1, 2}, Env: map[string]string{"A": "1"},
+			},
+		},
+	}
+
+	for i, test := range tests {
+		args := data{}
+		if errs := unmarshalOpt(&args, test.args); len(errs) != 0 {
+			t.Errorf("%d test, unexpected error %v", i, errs)
+		}
+
+		if !reflect.DeepEqual(args, test.expected) {
+			t.Errorf("%d test, expected %v but %v", i, test.expected, args)
+		}
+	}
+}
+
+// TestListModes tests the list tag of the slice fields.
+func TestListModes(t *testing.T) {
+	type data struct {
+		Replace []string  `opt:"r" def:"a,b" sep:","`
+		Append  []string  `opt:"a" sep:"," list:"append"`
+		Unique  []int     `opt:"u" sep:"," list:"unique"`
+		Sorted  []string  `opt:"s" sep:"," list:"unique,sort"`
+		Floats  []float64 `opt:"f" list:"sort"`
+	}
+
+	args := data{
+		Replace: []string{"x"},
+		Append:  []string{"x"},
+		Unique:  []int{7},
+	}
+	errs := unmarshalOpt(&args, []string{"./app", "-a", "y,x", "-u3,1,3",
+		"-u01", "-s", "b,c,a,b", "-f2.5", "-f-1", "-f2.5"})
+	if len(errs) != 0 {
+		t.Fatal(errs)
+	}
+
+	expected := data{
+		Replace: []string{"a", "b"},
+		Append:  []string{"x", "y", "x"},
+		Unique:  []int{3, 1},
+		Sorted:  []string{"a", "b", "c"},
+		Floats:  []float64{-1, 2.5, 2.5},
+	}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected %v but %v", expected, args)
+	}
+
+	// The values replace the default value.
+	errs = unmarshalOpt(&args, []string{"./app", "-r", "c", "-r", "d"})
+	if len(errs) != 0 {
+		t.Fatal(errs)
+	}
+
+	if r := strings.Join(args.Replace, ","); r != "c,d" {
+		t.Errorf("expected c,d but %s", r)
+	}
+
+	// The list tag is for the slices only.
+	for _, obj := range []interface{}{
+		&struct {
+			List []string `list:"any"`
+		}{},
+		&struct {
+			List [2]string `list:"sort"`
+		}{},
+	} {
+		if err := Check(obj); err == nil {
+			t.Errorf("expected an error for %T", obj)
+		}
+	}
+}
+
+// TestListOfLists tests the list of lists fields with sep2 tag.
+func TestListOfLists(t *testing.T) {
+	type data struct {
+		Route  [][]string `opt:"r" sep:";" sep2:","`
+		Point  [][2]int   `opt:"p" sep2:":" split:"trim"`
+		Matrix [2][]int   `opt:"m" sep:";" sep2:"," def:"1,2;3"`
+		Single [][]string `opt:"s"`
+	}
+
+	args := data{}
+	errs := unmarshalOpt(&args, []string{"./app", "-r", "a,b;c",
+		"-p", "1:2", "-p", " 3 : 4 ", "-p", "5", "-s", "x,y"})
+	if len(errs) != 0 {
+		t.Fatal(errs)
+	}
+
+	expected := data{
+		Route:  [][]string{{"a", "b"}, {"c"}},
+		Point:  [][2]int{{1, 2}, {3, 4}, {5, 0}},
+		Matrix: [2][]int{{1, 2}, {3}},
+		Single: [][]string{{"x,y"}},
+	}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected %v but %v", expected, args)
+	}
+
+	// The inner arrays have the same overflow checks as the arrays.
+	errs = unmarshalOpt(&args, []string{"./app", "-p", "1:2:3"})
+	if len(errs) != 1 {
+		t.Errorf("expected one error but %v", errs)
+	}
+
+	errs = unmarshalOpt(&args, []string{"./app", "-p", "1:x"})
+	if len(errs) != 1 {
+		t.Errorf("expected one error but %v", errs)
+	}
+
+	// The sep2 tag is for the list of lists only.
+	wrong := struct {
+		List []string `sep2:","`
+	}{}
+	if err := Check(&wrong); err == nil {
+		t.Error("expected an error for sep2 tag of the flat list")
+	}
+
+	if err := Check(&data{}); err != nil {
+		t.Error(err)
+	}
+}
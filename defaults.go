@@ -0,0 +1,103 @@
+package opt
+
+import (
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Defaulter is implemented by the structure that sets the default values
+// itself. The SetDefaults method is called after the default values from
+// the def tags are set and before the values from the command line are
+// applied, so the command line values always win. The SetDefaults of
+// the parent structure is called before the nested structures.
+type Defaulter interface {
+	SetDefaults()
+}
+
+// The providerPrefix is the prefix of the def tag value
+// that refers to the named default value provider.
+const providerPrefix = "@"
+
+var (
+	// The providers is a registry of the named default value providers.
+	providers = map[string]func() string{
+		"ncpu": func() string {
+			return strconv.Itoa(runtime.NumCPU())
+		},
+		"hostname": func() string {
+			name, _ := os.Hostname()
+			return name
+		},
+		"home": func() string {
+			home, _ := os.UserHomeDir()
+			return home
+		},
+	}
+
+	// The providersMu protects the providers.
+	providersMu sync.RWMutex
+)
+
+// RegisterDefault registers the named provider of the default value.
+// The field with def:"@name" tag gets the value returned by the fn
+// if the flag isn't set on the command line. Registering a provider
+// with the name that already exists replaces it, the nil fn removes it.
+//
+// Built-in providers: @ncpu - the number of logical CPUs, @hostname -
+// the host name, @home - the home directory of the current user.
+func RegisterDefault(name string, fn func() string) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+
+	if fn == nil {
+		delete(providers, name)
+		return
+	}
+
+	providers[name] = fn
+}
+
+// The getProvider returns the default value provider by name.
+func getProvider(name string) (func() string, bool) {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+
+	fn, ok := providers[name]
+	return fn, ok
+}
+
+// The defaultValue returns the effective default value of the field:
+// the value of the named provider if the def tag is @name and such
+// provider is registered, or the def tag value with the environment
+// variables expanded if the expand tag is set to true.
+func (tg *tagGroup) defaultValue() string {
+	def := tg.defValue
+	if name, ok := strings.CutPrefix(def, providerPrefix); ok {
+		if fn, ok := getProvider(name); ok {
+			return fn()
+		}
+	}
+
+	if tg.expand {
+		return expandEnv(def)
+	}
+
+	return def
+}
+
+// The expandEnv replaces ${VAR} or $VAR in the str with the values of the
+// environment variables. The ${VAR:-fallback} form is replaced with the
+// fallback if the VAR isn't set or is empty.
+func expandEnv(str string) string {
+	return os.Expand(str, func(key string) string {
+		name, fallback, ok := strings.Cut(key, ":-")
+		if v := os.Getenv(name); v != "" || !ok {
+			return v
+		}
+
+		return fallback
+	})
+}
@@ -0,0 +1,144 @@
+package opt
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// The defaulterArgs is example of the struct with Defaulter.
+type defaulterArgs struct {
+	Host string `opt:"host" def:"localhost"`
+	Port int    `opt:"p" def:"80"`
+	User string `opt:"user"`
+}
+
+// SetDefaults sets the default values of the defaulterArgs.
+func (d *defaulterArgs) SetDefaults() {
+	d.Port = d.Port + 8000 // the def tag value is already set
+	d.User = "guest"
+}
+
+// TestExpandEnv tests expandEnv function.
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("OPT_TEST_HOME", "/home/user")
+	t.Setenv("OPT_TEST_EMPTY", "")
+
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"${OPT_TEST_HOME}/.cache/app", "/home/user/.cache/app"},
+		{"$OPT_TEST_HOME/.cache", "/home/user/.cache"},
+		{"${OPT_TEST_NONE:-/tmp}/app", "/tmp/app"},
+		{"${OPT_TEST_EMPTY:-/tmp}/app", "/tmp/app"},
+		{"${OPT_TEST_HOME:-/tmp}/app", "/home/user/app"},
+		{"${OPT_TEST_NONE}/app", "/app"},
+		{"localhost", "localhost"},
+	}
+
+	for _, test := range tests {
+		if v := expandEnv(test.value); v != test.expected {
+			t.Errorf("expected %q but %q", test.expected, v)
+		}
+	}
+}
+
+// TestDefaultValue tests defaultValue method of the tagGroup.
+func TestDefaultValue(t *testing.T) {
+	t.Setenv("OPT_TEST_HOME", "/home/user")
+	RegisterDefault("opt-test", func() string { return "value" })
+	defer RegisterDefault("opt-test", nil)
+
+	ncpu := strconv.Itoa(runtime.NumCPU())
+	tests := []struct {
+		tg       tagGroup
+		expected string
+	}{
+		{tagGroup{defValue: "@ncpu"}, ncpu},
+		{tagGroup{defValue: "@opt-test"}, "value"},
+		{tagGroup{defValue: "@unknown"}, "@unknown"},
+		{tagGroup{defValue: "$OPT_TEST_HOME"}, "$OPT_TEST_HOME"},
+		{tagGroup{defValue: "$OPT_TEST_HOME", expand: true}, "/home/user"},
+	}
+
+	for _, test := range tests {
+		if v := test.tg.defaultValue(); v != test.expected {
+			t.Errorf("expected %q but %q", test.expected, v)
+		}
+	}
+
+	RegisterDefault("opt-test", nil)
+	if _, ok := getProvider("opt-test"); ok {
+		t.Error("the provider must be removed")
+	}
+}
+
+// TestUnmarshalDefaults tests Unmarshal with the default values
+// from the environment, providers and Defaulter.
+func TestUnmarshalDefaults(t *testing.T) {
+	t.Setenv("OPT_TEST_HOME", "/home/user")
+
+	type data struct {
+		Cache   string `opt:"cache" def:"${OPT_TEST_HOME}/.cache" expand:"true" help:"cache directory"`
+		Workers int    `opt:"w" def:"@ncpu" help:"number of workers"`
+		Raw     string `opt:"raw" def:"$OPT_TEST_HOME"`
+		Doc     string `opt:"?"`
+	}
+
+	args := data{}
+	err := Unmarshal(&args, WithArgs([]string{"./app"}))
+	if err != nil {
+		t.Error(err)
+	}
+
+	if args.Cache != "/home/user/.cache" || args.Raw != "$OPT_TEST_HOME" ||
+		args.Workers != runtime.NumCPU() {
+		t.Errorf("incorrect result %v", args)
+	}
+
+	if !strings.Contains(args.Doc, "(default: /home/user/.cache)") {
+		t.Errorf("expected expanded default value in help %q", args.Doc)
+	}
+
+	err = Unmarshal(&args, WithArgs([]string{"./app", "-w", "2"}))
+	if err != nil || args.Workers != 2 {
+		t.Errorf("expected 2 workers but %d (%v)", args.Workers, err)
+	}
+
+	// Defaulter.
+	tests := []struct {
+		args     []string
+		expected defaulterArgs
+	}{
+		{
+			[]string{"./app"},
+			defaulterArgs{Host: "localhost", Port: 8080, User: "guest"},
+		},
+		{
+			[]string{"./app", "-p", "90", "--user", "root"},
+			defaulterArgs{Host: "localhost", Port: 90, User: "root"},
+		},
+	}
+
+	for _, test := range tests {
+		args := defaulterArgs{}
+		if err := Unmarshal(&args, WithArgs(test.args)); err != nil {
+			t.Error(err)
+		}
+
+		if args != test.expected {
+			t.Errorf("expected %v but %v", test.expected, args)
+		}
+	}
+
+	// Wrong tag.
+	wrong := struct {
+		Cache string `expand:"yes"`
+	}{}
+
+	if err := Check(&wrong); err == nil {
+		t.Error("there must be an error for incorrect expand tag")
+	}
+}
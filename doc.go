@@ -13,7 +13,12 @@
 // - Provides default values through struct tags
 // - Generates help documentation automatically
 // - Supports grouped short flags (-abc equivalent to -a -b -c)
+// - Accepts attached short flag values (-p8080 or -p=8080)
+// - Accepts explicit empty values (--name= or -n "") to clear defaults
 // - Allows flag aliases through the alt tag
+// - Handles built-in --help and --version flags with ParseOrExit
+// - Expands response files (@path) with the WithResponseFiles option
+// - Splits a string into arguments by shell-like rules (Split)
 //
 // Supported field types:
 // - Basic types: int, int8, int16, int32, int64
@@ -21,18 +26,36 @@
 // - Floating point: float32, float64
 // - Other basic types: string, bool
 // - URL types: url.URL and *url.URL
+// - Pointers to the above types, nil if the option is not provided
+// - Optional[T] of the above types and encoding.TextUnmarshaler types
+// - ByteSize for the sizes in bytes, like 512K, 10MiB or 1.5GB
 // - Arrays and slices of the above types
+// - Lists of lists of the above types, like [][]string or [][2]int
+// - Maps with string keys and values of the above types
+// - Nested structures as groups of options (--db-host, --db-port)
 //
 // Struct tags:
 // - opt: Defines the primary flag name (required)
 // - alt: Defines an alternative flag name (optional)
-// - def: Sets the default value (optional)
-// - sep: Specifies list separator for array/slice types (optional)
+// - def: Sets the default value, "@name" uses a named provider (optional)
+// - expand: Enables ${VAR} and ${VAR:-fallback} expansion in def (optional)
+// - sep: Specifies list separator for array/slice/map types (optional)
+// - sep2: Specifies inner list separator for list of lists (optional)
+// - list: Sets slice mode: replace or append, unique, sort (optional)
+// - split: Sets splitting rules: csv or escape, trim, noempty (optional)
+// - kvsep: Specifies key/value separator for map types (optional)
+// - unit: Enables integer suffixes: bytes (10MiB) or si (2k) (optional)
+// - base: Sets integer base, 0 for Go literal syntax like 0xff (optional)
+// - dup: Specifies duplicate key policy for map types (optional)
+// - prefix: Specifies long flag prefix for nested structures (optional)
+// - strict: Reports repeated flags of non-list fields as error (optional)
 // - help: Provides help text for documentation (optional)
 //
 // Special opt tag values:
-// - "?" : Field will store generated help text
+// - "?" : Field will store generated help text (string or func() string)
 // - "[]": Field will store positional arguments
+// - "...": Field will store unknown flags and unconsumed arguments
+// - "--": Field will store raw arguments after -- or the stop point
 // - "-" : Field will be ignored during parsing
 // - "0", "1", ...: Field will store specific positional argument
 //
@@ -54,6 +77,10 @@
 //	    }
 //	}
 //
+// Or with the generic Parse function, which allocates the structure:
+//
+//	args, err := opt.Parse[Args]()
+//
 // Command line examples:
 //
 //	./app --host=localhost -p 8080 --debug
@@ -65,6 +92,8 @@
 // - Returns errors for invalid value types
 // - Returns errors for array/slice overflow
 // - Generates panic for invalid struct configuration
+// - Check reports all problems of the struct configuration without panic
+// - Returns errors of the BeforeParse, AfterParse and Validate hooks
 //
 // Thread safety:
 // The package is safe to use from multiple goroutines
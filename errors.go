@@ -0,0 +1,26 @@
+package opt
+
+import "strings"
+
+// Errors is a list of errors, for example, all problems in the
+// definition of the structure fields found by the Check function.
+//
+// The Errors implements the error interface and supports errors.Is
+// and errors.As functions for each error in the list.
+type Errors []error
+
+// Error returns the messages of all errors in the list,
+// each on a new line.
+func (e Errors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the list of errors.
+func (e Errors) Unwrap() []error {
+	return e
+}
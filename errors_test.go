@@ -0,0 +1,20 @@
+package opt
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestErrors tests Errors type.
+func TestErrors(t *testing.T) {
+	one, two := errors.New("one"), errors.New("two")
+	errs := Errors{one, two}
+
+	if v := errs.Error(); v != "one\ntwo" {
+		t.Errorf("expected %q but %q", "one\ntwo", v)
+	}
+
+	if !errors.Is(errs, two) {
+		t.Error("expected true for errors.Is")
+	}
+}
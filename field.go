@@ -1,11 +1,13 @@
 package opt
 
 import (
+	"encoding"
 	"errors"
 	"fmt"
 	"net/url"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
 	"unicode/utf8"
 
@@ -32,6 +34,46 @@ const (
 	// for the tagNameDefValue field if the struct field is a list.
 	tagNameSepList = "sep"
 
+	// The tagNameKeySep the identifier of the tag that sets the delimiter
+	// between the key and the value for the map fields.
+	tagNameKeySep = "kvsep"
+
+	// The tagNameDupKey the identifier of the tag that sets the policy
+	// for the duplicate keys of the map fields.
+	tagNameDupKey = "dup"
+
+	// The tagNamePrefix the identifier of the tag that sets the prefix
+	// for the long flags of the nested structure fields.
+	tagNamePrefix = "prefix"
+
+	// The tagNameStrict the identifier of the tag that enables the error
+	// for the repeated flag of the field which isn't a list.
+	tagNameStrict = "strict"
+
+	// The tagNameExpand the identifier of the tag that enables
+	// the expansion of the environment variables in the default value.
+	tagNameExpand = "expand"
+
+	// The tagNameList the identifier of the tag that sets the mode
+	// of the slice fields: replace or append, unique, sort.
+	tagNameList = "list"
+
+	// The tagNameSepInner the identifier of the tag that sets the delimiter
+	// of the inner list values if the struct field is a list of lists.
+	tagNameSepInner = "sep2"
+
+	// The tagNameBase the identifier of the tag that sets the base
+	// of the integer values, 0 for the Go literal syntax.
+	tagNameBase = "base"
+
+	// The tagNameUnit the identifier of the tag that enables the suffixes
+	// of the integer values, like 10MiB for the bytes or 2k for the counts.
+	tagNameUnit = "unit"
+
+	// The tagNameSplit the identifier of the tag that sets the rules
+	// of the splitting of the list values by the separator.
+	tagNameSplit = "split"
+
 	// The defValueIgnored is the value of the tagNameOption field that
 	// should be ignored during processing.
 	defValueIgnored = "-"
@@ -41,6 +83,35 @@ const (
 	// will not be divided into items, but will be considered as one item
 	// in the list.
 	defSep = ""
+
+	// The defKeySep sets the default delimiter between
+	// the key and the value for the map fields.
+	defKeySep = "="
+
+	// The dupKeyLast, dupKeyFirst and dupKeyError are the allowed values
+	// of the tagNameDupKey tag: the last value of the duplicate key wins,
+	// the first value wins or the duplicate key causes an error.
+	dupKeyLast  = "last"
+	dupKeyFirst = "first"
+	dupKeyError = "error"
+
+	// The listReplace, listAppend, listUnique and listSort are the allowed
+	// values of the tagNameList tag: the values replace the content of the
+	// slice or are appended to it, the repeated items are dropped, the
+	// items are sorted in ascending order.
+	listReplace = "replace"
+	listAppend  = "append"
+	listUnique  = "unique"
+	listSort    = "sort"
+
+	// The splitCSV, splitEscape, splitTrim and splitNoEmpty are the allowed
+	// values of the tagNameSplit tag: the items can be enclosed in double
+	// quotes or the separator can be escaped by backslash, the spaces
+	// around the items are removed, the empty items cause an error.
+	splitCSV     = "csv"
+	splitEscape  = "escape"
+	splitTrim    = "trim"
+	splitNoEmpty = "noempty"
 )
 
 var (
@@ -49,7 +120,7 @@ var (
 
 	// The shortFlagRgx a regular expression to check
 	// if a string is short option.
-	shortFlagRgx = regexp.MustCompile(`^(\?|\[\]|[A-Za-z]{1})$`)
+	shortFlagRgx = regexp.MustCompile(`^(\?|\[\]|\.\.\.|--|[A-Za-z]{1})$`)
 
 	// The shortFlagSafeRgx as the shortFlagRgx but without the
 	// ability to win special tags like: ?, [].
@@ -57,6 +128,10 @@ var (
 
 	// The longRgx a regular expression to check if a string is long option.
 	longFlagRgx = regexp.MustCompile(`^[A-Za-z]{1}[A-Za-z\-1-9]{1,}$`)
+
+	// The prefixRgx a regular expression to check if a string is prefix
+	// for the long flags of the nested structure fields.
+	prefixRgx = regexp.MustCompile(`^[a-z]{1}[a-z\-0-9]*$`)
 )
 
 // The tagGroup is the tag group of a field.
@@ -66,6 +141,21 @@ type tagGroup struct {
 	defValue  string // default value
 	helpMsg   string // help information about field
 	sepList   string // list delimiter for defValue
+	sepInner  string // inner list delimiter for the list of lists
+	keySep    string // key/value delimiter for map
+	dupKey    string // duplicate key policy for map
+	expand    bool   // true if expand env variables in defValue
+	strict    bool   // true if the repeated flag is an error
+	appendTo  bool   // true if append the values to the slice
+	unique    bool   // true if drop the repeated items of the slice
+	sorted    bool   // true if sort the items of the slice
+	splitMode string // splitCSV or splitEscape, empty for plain split
+	trimItems bool   // true if trim the spaces around the list items
+	noEmpty   bool   // true if the empty list item is an error
+	base      int    // base of the integer values, see hasBase
+	hasBase   bool   // true if the base is set by the base tag
+	unit      string // suffixes of the integer values, see unitBytes
+	isBool    bool   // true if the field is boolean, see isBool
 	isIgnored bool   // true if ignore the field
 }
 
@@ -74,11 +164,22 @@ type fieldCast struct {
 	fieldName string         // just field name
 	tagGroup  *tagGroup      // tga group
 	item      *reflect.Value // field instance
+	index     []int          // index sequence of the field (for nested)
 }
 
 // The fieldCastList is list of field data structure.
 type fieldCastList []*fieldCast
 
+// The flagName returns the name of the field's flag as it's written
+// on the command line: --long-flag or -s (if there is no long flag).
+func (fc *fieldCast) flagName() string {
+	if fc.tagGroup.longFlag != "" {
+		return "--" + fc.tagGroup.longFlag
+	}
+
+	return "-" + fc.tagGroup.shortFlag
+}
+
 // The flags function returns map of field's flags in opt and alt tags.
 func (fcl fieldCastList) flags() map[string]int {
 	result := make(map[string]int, len(fcl))
@@ -96,6 +197,28 @@ func (fcl fieldCastList) flags() map[string]int {
 	return result
 }
 
+// The boolFlags returns the flags of the boolean fields.
+func (fcl fieldCastList) boolFlags() map[string]bool {
+	result := make(map[string]bool)
+
+	for _, fc := range fcl {
+		if !fc.tagGroup.isBool {
+			continue
+		}
+
+		for _, flag := range []string{
+			fc.tagGroup.shortFlag,
+			fc.tagGroup.longFlag,
+		} {
+			if flag != "" {
+				result[flag] = true
+			}
+		}
+	}
+
+	return result
+}
+
 // The validateStruct checks whether the object is a pointer to the structure,
 // and returns reflect.Type and reflect.Value of the object. If the object is
 // not a pointer to the structure or object is nil, it returns an error.
@@ -119,69 +242,536 @@ func validateStruct(obj interface{}) (reflect.Type, reflect.Value, error) {
 
 // The getFieldCastList parses the structure fields and
 // returns list of the fieldCast.
+//
+// If the structure has problems in the field definitions, it returns
+// all of them as the Errors list.
+//
+// The type-level data of the fields is cached by the structure type,
+// only the binding of the fields to the obj instance is done each call.
 func getFieldCastList(obj interface{}) (fieldCastList, error) {
-	var result fieldCastList
-
 	// Check object type.
 	rt, rv, err := validateStruct(obj)
 	if err != nil {
-		return result, err
+		return nil, err
+	}
+
+	info := getStructInfo(rt.Elem())
+	return info.fields.bind(rv.Elem()), info.err
+}
+
+// The parseFieldCastList parses the fields of the rt structure type
+// and returns list of the fieldCast without field instances.
+//
+// If the structure has problems in the field definitions, it returns
+// all of them as the Errors list.
+func parseFieldCastList(rt reflect.Type) (fieldCastList, error) {
+	var (
+		result fieldCastList
+		errs   Errors
+	)
+
+	// Collect fields of the structure and all nested structures.
+	collectFields(rt, "", "", nil, nil, &result, &errs)
+
+	// The flag names must be unique across the whole tree
+	// of the nested structures.
+	// The special tags like ?, [] and positional arguments can be repeated.
+	names := make(map[string][]string, len(result))
+	for _, fc := range result {
+		for _, flag := range []string{
+			fc.tagGroup.shortFlag,
+			fc.tagGroup.longFlag,
+		} {
+			if flag != "" && !isSpecialFlag(flag) {
+				names[flag] = append(names[flag], fc.fieldName)
+			}
+		}
+	}
+
+	for _, fc := range result {
+		for _, flag := range []string{
+			fc.tagGroup.shortFlag,
+			fc.tagGroup.longFlag,
+		} {
+			// Report the conflict once, for the first field only.
+			if fields := names[flag]; len(fields) > 1 &&
+				fields[0] == fc.fieldName {
+				errs = append(errs, fmt.Errorf(
+					"%s flag is declared more than once in %s fields",
+					flag, strings.Join(fields, ", "),
+				))
+			}
+		}
 	}
 
-	elem := rv.Elem()
-	urlS, urlP := reflect.TypeOf(url.URL{}), reflect.TypeOf((*url.URL)(nil))
-	for i := 0; i < elem.NumField(); i++ {
+	if len(errs) != 0 {
+		return result, errs
+	}
+
+	return result, nil
+}
+
+// The bind returns a copy of the fieldCastList where each field
+// is bound to the field instance of the elem structure. The nil
+// pointers to the nested structures are allocated.
+func (fcl fieldCastList) bind(elem reflect.Value) fieldCastList {
+	// Allocate memory for all fields at once.
+	result := make(fieldCastList, len(fcl))
+	casts := make([]fieldCast, len(fcl))
+	items := make([]reflect.Value, len(fcl))
+
+	for i, fc := range fcl {
+		item := elem
+		for j, index := range fc.index {
+			// All items except the last one are the nested structures.
+			if j != 0 && item.Kind() == reflect.Ptr {
+				if item.IsNil() {
+					item.Set(reflect.New(item.Type().Elem()))
+				}
+				item = item.Elem()
+			}
+			item = item.Field(index)
+		}
+
+		items[i] = item
+		casts[i] = *fc
+		casts[i].item = &items[i]
+		result[i] = &casts[i]
+	}
+
+	return result
+}
+
+// The collectFields parses the fields of the rt structure type and appends
+// them to the result. The nested structures are parsed recursively, the
+// long flags of their fields get the prefix, the path is a chain of the
+// field names of the parent structures, the index is a chain of their
+// indexes.
+//
+// Don't interrupt on the first problem of the field definitions,
+// all of them are appended to the errs list.
+func collectFields(rt reflect.Type, prefix, path string, index []int,
+	parents []reflect.Type, result *fieldCastList, errs *Errors) {
+	parents = append(parents[:len(parents):len(parents)], rt)
+	for i := 0; i < rt.NumField(); i++ {
 		// Get tag data from the field.
-		field := rt.Elem().Field(i)
+		field := rt.Field(i)
+		name := path + field.Name
+		fieldIndex := append(append(make([]int, 0, len(index)+1),
+			index...), i)
+
+		// The field can be ignored by the opt:"-" tag.
+		optTag := strings.TrimSpace(field.Tag.Get(tagNameOpt))
+		if optTag == defValueIgnored {
+			continue
+		}
+
+		// The nested structure is a group of options.
+		if isNested(field.Type) {
+			nested := field.Type
+			if nested.Kind() == reflect.Ptr {
+				nested = nested.Elem()
+				if !field.IsExported() {
+					*errs = append(*errs,
+						fmt.Errorf("%s field cannot be set", name))
+					continue
+				}
+			}
+
+			if nested.NumField() == 0 {
+				*errs = append(*errs, fmt.Errorf(
+					"%s field should be a non-empty struct", name))
+				continue
+			}
+
+			// The structure can't contain itself at any depth,
+			// otherwise the group of options is endless.
+			if hasType(parents, nested) {
+				*errs = append(*errs, fmt.Errorf(
+					"%s field has recursive nested structure", name))
+				continue
+			}
+
+			p, err := getPrefix(field)
+			if err != nil {
+				*errs = append(*errs, fmt.Errorf("%s field: %v", name, err))
+				continue
+			}
+
+			if prefix != "" && p != "" {
+				p = prefix + "-" + p
+			} else if p == "" {
+				p = prefix
+			}
+
+			collectFields(nested, p, name+".", fieldIndex, parents,
+				result, errs)
+			continue
+		}
+
+		// Get tag group. The dashes are trimmed from the flag names,
+		// except the field for the raw arguments.
+		optValue := strings.Trim(optTag, " -")
+		if optTag == rawFlag {
+			optValue = rawFlag
+		}
 
-		// Get tag group.
 		tg, err := getTagGroup(
 			field.Name,
-			strings.Trim(field.Tag.Get(tagNameOpt), " -"),
+			optValue,
 			strings.Trim(field.Tag.Get(tagNameAlt), " -"),
 			field.Tag.Get(tagNameDefValue),
 			field.Tag.Get(tagNameSepList),
 			field.Tag.Get(tagNameHelpMsg),
 		)
 
+		if err == nil {
+			err = setTagOptions(&tg, field.Tag)
+		}
+
+		// The long flags of the nested structure fields get the prefix.
+		if err == nil && prefix != "" && tg.longFlag != "" {
+			tg.longFlag = prefix + "-" + tg.longFlag
+			if utf8.RuneCountInString(tg.longFlag) > 32 {
+				err = fmt.Errorf("%s is a very long name, max 32 chars",
+					tg.longFlag)
+			}
+		}
+
 		if err != nil {
-			return result, err
+			*errs = append(*errs, fmt.Errorf("%s field: %v", name, err))
+			continue
 		} else if tg.isIgnored {
 			continue
 		}
 
 		// Collect fields for further analysis.
-		item := elem.FieldByName(field.Name)
-		fc := fieldCast{fieldName: field.Name, tagGroup: &tg, item: &item}
+		tg.isBool = isBool(field.Type)
+		fc := fieldCast{fieldName: name, tagGroup: &tg, index: fieldIndex}
 
-		kind := fc.item.Kind()
+		kind := field.Type.Kind()
 		switch f := fc.tagGroup.shortFlag; {
-		case f == "?" && kind != reflect.String:
-			// To load doc, the field must be of the string type.
-			err = fmt.Errorf("%s field should be a string", fc.fieldName)
+		case f == "?" && kind != reflect.String && field.Type != helpFuncType:
+			// To load doc, the field must be of the string type
+			// or func() string type for lazy generation of the doc.
+			err = fmt.Errorf("%s field should be a string or func() string",
+				fc.fieldName)
 		case f == "[]" && kind != reflect.Array && kind != reflect.Slice:
 			// To load positional arguments,
 			// the field must be of the slice type.
 			err = fmt.Errorf("%s field should be a list", fc.fieldName)
-		case kind == reflect.Struct && fc.item.Type() != urlS:
-			// Supported url.URL struct only.
-			err = fmt.Errorf("%s field has invalid type", fc.fieldName)
-		case kind == reflect.Ptr:
-			// Pointer to the structure *url.URL only.
-			k, t := fc.item.Type().Elem().Kind(), fc.item.Type()
-			if k == reflect.Struct && t != urlP {
+		case (f == restFlag || f == rawFlag) &&
+			field.Type != reflect.TypeOf([]string{}):
+			// To keep the arguments which aren't consumed by the flags
+			// or the raw arguments, the field must be of the []string type.
+			err = fmt.Errorf("%s field should be a []string", fc.fieldName)
+		case kind != reflect.Slice && hasTag(field.Tag, tagNameList):
+			// The list modes are for the slices only.
+			err = fmt.Errorf("%s field should be a slice for %s tag",
+				fc.fieldName, tagNameList)
+		case kind != reflect.Slice && kind != reflect.Array &&
+			kind != reflect.Map && hasTag(field.Tag, tagNameSplit):
+			// The rules of the splitting are for the lists only.
+			err = fmt.Errorf("%s field should be a list for %s tag",
+				fc.fieldName, tagNameSplit)
+		case hasTag(field.Tag, tagNameSepInner) &&
+			!(isList(field.Type) && isList(field.Type.Elem())):
+			// The inner delimiter is for the list of lists only.
+			err = fmt.Errorf("%s field should be a list of lists for %s tag",
+				fc.fieldName, tagNameSepInner)
+		case hasTag(field.Tag, tagNameBase) && !isInteger(field.Type):
+			// The base is for the integer values only.
+			err = fmt.Errorf("%s field should be an integer for %s tag",
+				fc.fieldName, tagNameBase)
+		case hasTag(field.Tag, tagNameUnit) && !isInteger(field.Type):
+			// The units are for the integer values only.
+			err = fmt.Errorf("%s field should be an integer for %s tag",
+				fc.fieldName, tagNameUnit)
+		case kind == reflect.Map:
+			// The map with string keys and scalar values only.
+			t := field.Type
+			if t.Key().Kind() != reflect.String || !isScalar(t.Elem()) {
 				err = fmt.Errorf("%s field has invalid type", fc.fieldName)
 			}
 		}
 
 		if err != nil {
-			return result, err
+			*errs = append(*errs, err)
+			continue
 		}
 
-		result = append(result, &fc)
+		*result = append(*result, &fc)
 	}
+}
 
-	return result, nil
+// The hasTag returns true if the tag with the name is specified.
+func hasTag(tag reflect.StructTag, name string) bool {
+	_, ok := tag.Lookup(name)
+	return ok
+}
+
+// The helpFuncType is the type of the doc-field for lazy generation
+// of the help information.
+var helpFuncType = reflect.TypeOf((func() string)(nil))
+
+// The isSpecialFlag returns true if the flag is a special opt tag value:
+// ?, [], ..., -- or number of the positional argument.
+func isSpecialFlag(flag string) bool {
+	return flag == "?" || flag == "[]" || flag == restFlag ||
+		flag == rawFlag || orderFlagRgx.MatchString(flag)
+}
+
+// The textUnmarshalerType is the type of encoding.TextUnmarshaler.
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).
+	Elem()
+
+// The optional is implemented by the Optional of any value type.
+type optional interface {
+	valueType() reflect.Type
+}
+
+// The isTextUnmarshaler returns true if the pointer to the value of the
+// t type implements encoding.TextUnmarshaler, so the value is converted
+// from the string by the UnmarshalText method.
+//
+// The predeclared and unnamed types, which can't have methods, have an
+// empty package path, so they are checked quickly.
+func isTextUnmarshaler(t reflect.Type) bool {
+	return t.PkgPath() != "" && t.Kind() != reflect.Ptr &&
+		reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// The hasType returns true if the types list contains the t type.
+func hasType(types []reflect.Type, t reflect.Type) bool {
+	for _, item := range types {
+		if item == t {
+			return true
+		}
+	}
+
+	return false
+}
+
+// The isNested returns true if the field of the t type is a nested
+// structure (or pointer to it) that should be parsed as group of options.
+func isNested(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.Struct && t != reflect.TypeOf(url.URL{}) &&
+		!isTextUnmarshaler(t)
+}
+
+// The getPrefix returns the prefix for the long flags of the nested
+// structure fields. The prefix can be set by the prefix tag, an empty
+// tag value flattens the nested structure. Without the tag, the prefix
+// is the field name in kebab case, the embedded structures are flattened.
+func getPrefix(field reflect.StructField) (string, error) {
+	prefix, ok := field.Tag.Lookup(tagNamePrefix)
+	switch {
+	case ok:
+		prefix = strings.ToLower(strings.Trim(prefix, " -"))
+	case field.Anonymous:
+		return "", nil
+	default:
+		prefix = strings.ToLower(field.Name)
+		if kebab, err := scs.PascalToKebab(field.Name); err == nil {
+			prefix = kebab
+		}
+	}
+
+	if prefix != "" && !prefixRgx.MatchString(prefix) {
+		return "", fmt.Errorf("invalid %s tag value %s",
+			tagNamePrefix, prefix)
+	}
+
+	return prefix, nil
+}
+
+// The isSupported returns true if the field of the t type can
+// be filled from the command line: scalar types, pointers to them,
+// slices and arrays of them, maps with string keys and scalar values.
+func isSupported(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return isScalar(t.Elem())
+	case reflect.Slice, reflect.Array:
+		// The list of lists, like [][]string or [][2]int, is supported.
+		e := t.Elem()
+		return isScalar(e) || isList(e) && isScalar(e.Elem())
+	case reflect.Map:
+		return t.Key().Kind() == reflect.String && isScalar(t.Elem())
+	}
+
+	return isScalar(t)
+}
+
+// The isList returns true if the t type is a slice or an array which
+// is filled item by item, not by the UnmarshalText method.
+func isList(t reflect.Type) bool {
+	kind := t.Kind()
+	return (kind == reflect.Slice || kind == reflect.Array) &&
+		!isTextUnmarshaler(t)
+}
+
+// The isBool returns true if the values of the t type are booleans,
+// like bool, *bool, []bool or Optional[bool], but not map[string]bool.
+func isBool(t reflect.Type) bool {
+	if t.Kind() == reflect.Map {
+		return false
+	}
+
+	// The Optional is boolean if its value type is boolean.
+	t = scalarType(t)
+	if o, ok := reflect.New(t).Interface().(optional); ok {
+		return isBool(o.valueType())
+	}
+
+	return t.Kind() == reflect.Bool
+}
+
+// The isInteger returns true if the values of the t type
+// are integers, like int, []uint8 or map[string]int64.
+func isInteger(t reflect.Type) bool {
+	t = scalarType(t)
+	if isTextUnmarshaler(t) {
+		return false
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32, reflect.Uint64:
+		return true
+	}
+
+	return false
+}
+
+// The isScalar returns true if the type can be converted from one string
+// value: numbers, strings, booleans, url.URL, *url.URL and the types which
+// implement encoding.TextUnmarshaler.
+func isScalar(t reflect.Type) bool {
+	if isTextUnmarshaler(t) {
+		// The Optional is supported if its value type is supported.
+		if o, ok := reflect.New(t).Interface().(optional); ok {
+			return isScalar(o.valueType())
+		}
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64,
+		reflect.Bool, reflect.String:
+		return true
+	case reflect.Struct:
+		return t == reflect.TypeOf(url.URL{})
+	case reflect.Ptr:
+		return t == reflect.TypeOf((*url.URL)(nil))
+	}
+
+	return false
+}
+
+// The setTagOptions sets into tagGroup the values of the additional
+// tags that customize the parsing of the specific field types.
+func setTagOptions(tg *tagGroup, tag reflect.StructTag) error {
+	// Key/value delimiter for the map fields.
+	tg.keySep = defKeySep
+	if v, ok := tag.Lookup(tagNameKeySep); ok {
+		if v == "" {
+			return fmt.Errorf("%s tag cannot be empty", tagNameKeySep)
+		}
+		tg.keySep = v
+	}
+
+	// Duplicate key policy for the map fields.
+	switch v := tag.Get(tagNameDupKey); v {
+	case "":
+		tg.dupKey = dupKeyLast
+	case dupKeyLast, dupKeyFirst, dupKeyError:
+		tg.dupKey = v
+	default:
+		return fmt.Errorf("invalid %s tag value %s", tagNameDupKey, v)
+	}
+
+	// Expansion of the environment variables in the default value.
+	if v, ok := tag.Lookup(tagNameExpand); ok {
+		expand, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid %s tag value %s", tagNameExpand, v)
+		}
+		tg.expand = expand
+	}
+
+	// Error for the repeated flag of the field which isn't a list.
+	if v, ok := tag.Lookup(tagNameStrict); ok {
+		strict, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid %s tag value %s", tagNameStrict, v)
+		}
+		tg.strict = strict
+	}
+
+	// Mode of the slice fields, like: append,unique.
+	if v, ok := tag.Lookup(tagNameList); ok {
+		for _, mode := range strings.Split(v, ",") {
+			switch strings.TrimSpace(mode) {
+			case listReplace:
+				tg.appendTo = false
+			case listAppend:
+				tg.appendTo = true
+			case listUnique:
+				tg.unique = true
+			case listSort:
+				tg.sorted = true
+			default:
+				return fmt.Errorf("invalid %s tag value %s", tagNameList, v)
+			}
+		}
+	}
+
+	// Delimiter of the inner list values for the list of lists.
+	tg.sepInner = tag.Get(tagNameSepInner)
+
+	// Base of the integer values: 0 for Go literal syntax or 2..36.
+	if v, ok := tag.Lookup(tagNameBase); ok {
+		base, err := strconv.Atoi(v)
+		if err != nil || base < 0 || base == 1 || base > 36 {
+			return fmt.Errorf("invalid %s tag value %s", tagNameBase, v)
+		}
+		tg.base, tg.hasBase = base, true
+	}
+
+	// Suffixes of the integer values: bytes or si.
+	switch v := tag.Get(tagNameUnit); v {
+	case "", unitBytes, unitSI:
+		tg.unit = v
+	default:
+		return fmt.Errorf("invalid %s tag value %s", tagNameUnit, v)
+	}
+
+	// Rules of the splitting of the list values, like: csv,trim.
+	if v, ok := tag.Lookup(tagNameSplit); ok {
+		for _, rule := range strings.Split(v, ",") {
+			switch rule = strings.TrimSpace(rule); {
+			case (rule == splitCSV || rule == splitEscape) &&
+				tg.splitMode == "":
+				tg.splitMode = rule
+			case rule == splitTrim:
+				tg.trimItems = true
+			case rule == splitNoEmpty:
+				tg.noEmpty = true
+			default:
+				return fmt.Errorf("invalid %s tag value %s", tagNameSplit, v)
+			}
+		}
+	}
+
+	return nil
 }
 
 // getTagGroup returns a tagGroup with the specified tag values.
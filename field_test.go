@@ -2,6 +2,7 @@ package opt
 
 import (
 	"net/url"
+	"strings"
 	"testing"
 )
 
@@ -250,3 +251,132 @@ func TestGetTagGroupWrongTag(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+// TestGetFieldCastListMap tests getFieldCastList function
+// for field as a map.
+func TestGetFieldCastListMap(t *testing.T) {
+	var (
+		objWrongKey = struct {
+			Object map[int]string // supports string keys only
+		}{}
+		objWrongValue = struct {
+			Object map[string][]string // supports scalar values only
+		}{}
+		objWrongDup = struct {
+			Object map[string]string `dup:"any"`
+		}{}
+		objCorrect = struct {
+			Object map[string]*url.URL `kvsep:":" dup:"error"`
+		}{}
+	)
+
+	if _, err := getFieldCastList(&objWrongKey); err == nil {
+		t.Error("there must be an error for map with int keys")
+	}
+
+	if _, err := getFieldCastList(&objWrongValue); err == nil {
+		t.Error("there must be an error for map with list values")
+	}
+
+	if _, err := getFieldCastList(&objWrongDup); err == nil {
+		t.Error("there must be an error for incorrect dup tag")
+	}
+
+	if _, err := getFieldCastList(&objCorrect); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestGetFieldCastListNested tests getFieldCastList function
+// for nested structures.
+func TestGetFieldCastListNested(t *testing.T) {
+	type server struct {
+		Host string
+		Port int `opt:"p" alt:"port"`
+	}
+
+	var (
+		objCorrect = struct {
+			Server server // --server-host, -p, --server-port
+			Client struct {
+				Host string // --client-host
+			}
+		}{}
+		objShortCollision = struct {
+			Server server
+			Client server `prefix:"client"` // -p is declared twice
+		}{}
+		objLongCollision = struct {
+			Host   string
+			Server struct {
+				Host string
+			} `prefix:""` // --host is declared twice
+		}{}
+		objWrongPrefix = struct {
+			Server server `prefix:"***"`
+		}{}
+	)
+
+	fcl, err := getFieldCastList(&objCorrect)
+	if err != nil {
+		t.Error(err)
+	}
+
+	flags := fcl.flags()
+	for _, flag := range []string{"server-host", "server-port", "p",
+		"client-host"} {
+		if flags[flag] != 1 {
+			t.Errorf("expected %s flag in %v", flag, flags)
+		}
+	}
+
+	if _, err := getFieldCastList(&objShortCollision); err == nil {
+		t.Error("there must be an error for duplicate short flag")
+	}
+
+	if _, err := getFieldCastList(&objLongCollision); err == nil {
+		t.Error("there must be an error for duplicate long flag")
+	}
+
+	if _, err := getFieldCastList(&objWrongPrefix); err == nil {
+		t.Error("there must be an error for incorrect prefix tag")
+	}
+}
+
+// TestGetFieldCastListRecursive tests getFieldCastList function
+// for recursive nested structures.
+func TestGetFieldCastListRecursive(t *testing.T) {
+	type node struct {
+		Name string
+		Next *node
+	}
+
+	type tree struct {
+		Name  string
+		Child struct {
+			Parent *tree
+		}
+	}
+
+	for _, obj := range []interface{}{&node{}, &tree{}} {
+		_, err := getFieldCastList(obj)
+		if err == nil || !strings.Contains(err.Error(),
+			"recursive nested structure") {
+			t.Errorf("expected recursive nested structure error "+
+				"for %T but %v", obj, err)
+		}
+
+		if err := Check(obj); err == nil {
+			t.Errorf("expected an error of Check for %T", obj)
+		}
+	}
+
+	// Note: An incorrect storage object causes the function to cause panic!
+	defer func() {
+		if err := recover(); err == nil {
+			t.Error("an error is expected for recursive nested structure")
+		}
+	}()
+
+	unmarshalOpt(&node{}, []string{"./app"}) // panic is expected
+}
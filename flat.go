@@ -0,0 +1,104 @@
+package opt
+
+import (
+	"math"
+	"strconv"
+)
+
+// ParseFlat parses the command line without declaring the structure,
+// for example, for wrapper scripts or debugging. Every flag is accepted:
+// any long flag, and every letter of the short flag group is a flag until
+// the first non-letter character, which starts the value (-p8080 is p flag
+// with 8080 value, -abc are a, b and c flags).
+//
+// The args are the command line arguments with the path to the
+// application, like os.Args. Returns the values of the flags by their
+// names (without dashes) in the order of the command line, and the
+// positional arguments without the path to the application.
+//
+// Without the structure, it's unknown which flags are boolean, so the
+// flag without the attached value (the last flag of the short group)
+// takes the next argument as the value if it isn't a flag.
+// The flag gets "true" value only if it's followed by another flag, by
+// the "--" or if it's the last argument. The lone dash (like stdin for
+// many tools) and the dash followed by a digit aren't flags, they are
+// the values or the positional arguments, like --offset -5 and -.
+// For example:
+//
+//	// ./app --user=John -U Bob -dv 5 10
+//	flags, pos, err := opt.ParseFlat(os.Args)
+//	// flags: map[U:[Bob] d:[true] user:[John] v:[5]]
+//	// pos: [10]
+//
+//	// ./app --user=John -U Bob -dv -- 5 10
+//	// flags: map[U:[Bob] d:[true] user:[John] v:[true]]
+//	// pos: [5 10]
+func ParseFlat(args []string) (map[string][]string, []string, error) {
+	am, cfg := argMap{}, config{permissive: true}
+	if err := am.parseWith(args, nil, &cfg); err != nil {
+		return nil, nil, err
+	}
+
+	// The positional arguments are stored by their
+	// numbers, they are returned separately.
+	pos := am.posValues()
+	result := am.asFlat()
+	for key := range result {
+		if orderFlagRgx.MatchString(key) {
+			delete(result, key)
+		}
+	}
+
+	return result, pos, nil
+}
+
+// ParseAny is the same as ParseFlat, but the values of the flags are
+// converted to the inferred types: int64, float64, bool or string.
+// The flag with one value has the value itself, the flag with several
+// values has []any of them, for example:
+//
+//	// ./app --port 8080 -v --user John --user Bob --ratio=0.5
+//	flags, pos, err := opt.ParseAny(os.Args)
+//	// flags: map[port:8080 ratio:0.5 user:[John Bob] v:true]
+func ParseAny(args []string) (map[string]interface{}, []string, error) {
+	flat, pos, err := ParseFlat(args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := make(map[string]interface{}, len(flat))
+	for key, values := range flat {
+		if len(values) == 1 {
+			result[key] = inferValue(values[0])
+			continue
+		}
+
+		items := make([]interface{}, 0, len(values))
+		for _, value := range values {
+			items = append(items, inferValue(value))
+		}
+		result[key] = items
+	}
+
+	return result, pos, nil
+}
+
+// The inferValue converts the value to the first suitable type: int64,
+// float64 (finite numbers only), bool (true or false only) or string.
+func inferValue(value string) interface{} {
+	if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return v
+	} else if v, err := strconv.ParseFloat(value, 64); err == nil &&
+		!math.IsInf(v, 0) && !math.IsNaN(v) {
+		return v
+	}
+
+	switch value {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+
+	return value
+}
@@ -0,0 +1,112 @@
+package opt
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestParseFlat tests ParseFlat function.
+func TestParseFlat(t *testing.T) {
+	args := []string{"./app", "5", "--user=John", "-U", "Bob", "-dv",
+		"-p8080", "--no-cache", "--", "10", "-x"}
+	flags, pos, err := ParseFlat(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string][]string{
+		"user":     {"John"},
+		"U":        {"Bob"},
+		"d":        {"true"},
+		"v":        {"true"},
+		"p":        {"8080"},
+		"no-cache": {"true"},
+	}
+	if !reflect.DeepEqual(flags, expected) {
+		t.Errorf("expected %v but %v", expected, flags)
+	}
+
+	if v := []string{"5", "10", "-x"}; !reflect.DeepEqual(pos, v) {
+		t.Errorf("expected %v but %v", v, pos)
+	}
+
+	// The last flag of the short group takes the next argument.
+	flags, pos, err = ParseFlat([]string{"./app", "-dv", "5", "10"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = map[string][]string{"d": {"true"}, "v": {"5"}}
+	if !reflect.DeepEqual(flags, expected) {
+		t.Errorf("expected %v but %v", expected, flags)
+	}
+
+	if v := []string{"10"}; !reflect.DeepEqual(pos, v) {
+		t.Errorf("expected %v but %v", v, pos)
+	}
+
+	// The lone dash and the negative numbers are values.
+	flags, pos, err = ParseFlat([]string{"./app", "--offset", "-5", "-",
+		"-n", "-1.5", "-2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = map[string][]string{"offset": {"-5"}, "n": {"-1.5"}}
+	if !reflect.DeepEqual(flags, expected) {
+		t.Errorf("expected %v but %v", expected, flags)
+	}
+
+	if v := []string{"-", "-2"}; !reflect.DeepEqual(pos, v) {
+		t.Errorf("expected %v but %v", v, pos)
+	}
+
+	for _, args := range [][]string{
+		{"./app", "-=5"},
+		{"./app", "--=5"},
+	} {
+		if _, _, err := ParseFlat(args); err == nil {
+			t.Errorf("expected an error for %v", args)
+		}
+	}
+}
+
+// TestParseAny tests ParseAny function.
+func TestParseAny(t *testing.T) {
+	args := []string{"./app", "--port", "8080", "-v", "--user", "John",
+		"--user", "Bob", "--ratio=0.5", "--name=NaN", "--debug=false", "a"}
+	flags, pos, err := ParseAny(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"port":  int64(8080),
+		"v":     true,
+		"user":  []interface{}{"John", "Bob"},
+		"ratio": 0.5,
+		"name":  "NaN",
+		"debug": false,
+	}
+	if !reflect.DeepEqual(flags, expected) {
+		t.Errorf("expected %v but %v", expected, flags)
+	}
+
+	if v := []string{"a"}; !reflect.DeepEqual(pos, v) {
+		t.Errorf("expected %v but %v", v, pos)
+	}
+
+	flags, pos, err = ParseAny([]string{"./app", "--offset", "-5", "-"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flags["offset"] != int64(-5) || !reflect.DeepEqual(pos, []string{"-"}) {
+		t.Errorf("expected -5 offset and - positional but %v, %v",
+			flags, pos)
+	}
+
+	if _, _, err := ParseAny([]string{"./app", "-=5"}); err == nil {
+		t.Error("expected an error")
+	}
+}
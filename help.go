@@ -142,21 +142,30 @@ func getOptionBlock(fcl fieldCastList, am argMap) (string, int) {
 				posArgsExists = true
 			}
 			fallthrough
-		case fc.tagGroup.shortFlag == "?":
-			// Field for uploading documentation.
+		case flag == "?" || flag == restFlag || flag == rawFlag:
+			// Field for uploading documentation,
+			// for the unknown or raw arguments.
 			fallthrough
 		case orderFlagRgx.Match([]byte(flag)):
 			// Fixed positional argument.
 			continue
 		}
 
-		// Make prefix from the items.
+		// Make prefix from the items. The help message shows the
+		// default value from the def tag with the resolved environment
+		// variables and providers, but not the value of SetDefaults.
+		help := fc.tagGroup.helpMsg
+		if help != "" && fc.tagGroup.defValue != "" {
+			help = fmt.Sprintf("%s (default: %s)",
+				help, fc.defaultText())
+		}
+
 		p, l := getOptionPrefix(fc.tagGroup.shortFlag, fc.tagGroup.longFlag)
 		items = append(items, optionItems{
 			fc.tagGroup.shortFlag,
 			fc.tagGroup.longFlag,
 			p,
-			fc.tagGroup.helpMsg,
+			help,
 		})
 
 		// Determine the largest prefix of arguments. The option is doesn't
@@ -328,3 +337,10 @@ func getHelp(fcl fieldCastList, am argMap) string {
 
 	return strings.Join(result, "\n")
 }
+
+// The getLazyHelp returns function that generates help on using command
+// line options on demand. The help text doesn't depend on the values of
+// the command line, so the argMap isn't captured by the function.
+func getLazyHelp(fcl fieldCastList) func() string {
+	return func() string { return getHelp(fcl, argMap{}) }
+}
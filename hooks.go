@@ -0,0 +1,183 @@
+package opt
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// BeforeParser is implemented by the structure that should be prepared
+// before the parsing. The BeforeParse method is called before any field
+// is set, its error is added to the result of the parsing.
+type BeforeParser interface {
+	BeforeParse() error
+}
+
+// AfterParser is implemented by the structure that processes its fields
+// after the parsing, for example: normalizes paths or derives fields.
+// The AfterParse method is called after all fields are set and before
+// the Validate method.
+type AfterParser interface {
+	AfterParse() error
+}
+
+// Validator is implemented by the structure that checks its invariants.
+// The Validate method is called after all fields are set and processed
+// by the AfterParse method, its error is added to the result of the parsing.
+type Validator interface {
+	Validate() error
+}
+
+// The nestedStruct is the nested structure that is parsed
+// as group of options.
+type nestedStruct struct {
+	name  string // field name with the parent path, like Server.TLS
+	index []int  // index sequence of the field
+}
+
+// The collectNested appends to the result the nested structures of the rt
+// structure type in the same order as the collectFields parses them: the
+// parent structure before its nested structures. The recursive nested
+// structures are skipped, the collectFields reports them as errors.
+func collectNested(rt reflect.Type, path string, index []int,
+	parents []reflect.Type, result *[]nestedStruct) {
+	parents = append(parents[:len(parents):len(parents)], rt)
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		optTag := strings.TrimSpace(field.Tag.Get(tagNameOpt))
+		if optTag == defValueIgnored || !isNested(field.Type) {
+			continue
+		}
+
+		nested := field.Type
+		if nested.Kind() == reflect.Ptr {
+			nested = nested.Elem()
+		}
+
+		if hasType(parents, nested) {
+			continue
+		}
+
+		name := path + field.Name
+		fieldIndex := append(append(make([]int, 0, len(index)+1),
+			index...), i)
+		*result = append(*result, nestedStruct{name, fieldIndex})
+
+		collectNested(nested, name+".", fieldIndex, parents, result)
+	}
+}
+
+// The hookTarget is the structure which can implement the lifecycle hooks.
+type hookTarget struct {
+	name string      // field name of the nested structure, empty for root
+	obj  interface{} // pointer to the structure
+}
+
+// The hookTargets is list of the structures which can implement the
+// lifecycle hooks: the root structure and its nested structures.
+type hookTargets []hookTarget
+
+// The appendHookTargets appends to the hts the obj and its nested
+// structures of the elem instance. The nil pointers to the nested
+// structures and the nested structures in the unexported fields
+// are skipped.
+func appendHookTargets(hts hookTargets, obj interface{}, elem reflect.Value,
+	nested []nestedStruct) hookTargets {
+	result := append(hts, hookTarget{obj: obj})
+
+next:
+	for _, ns := range nested {
+		item := elem
+		for j, index := range ns.index {
+			if j != 0 && item.Kind() == reflect.Ptr {
+				if item.IsNil() {
+					continue next
+				}
+				item = item.Elem()
+			}
+			item = item.Field(index)
+		}
+
+		if item.Kind() != reflect.Ptr {
+			item = item.Addr()
+		} else if item.IsNil() {
+			continue
+		}
+
+		if item.CanInterface() {
+			result = append(result, hookTarget{ns.name, item.Interface()})
+		}
+	}
+
+	return result
+}
+
+// The call calls the fn for each target and returns all errors, the errors
+// of the nested structures get the field name. The parent structures are
+// processed before the nested ones, or after them if the reverse is true.
+func (hts hookTargets) call(reverse bool, fn func(interface{}) error) []error {
+	var errs []error
+
+	for i := range hts {
+		ht := hts[i]
+		if reverse {
+			ht = hts[len(hts)-1-i]
+		}
+
+		err := fn(ht.obj)
+		switch {
+		case err == nil:
+			continue
+		case ht.name != "":
+			err = fmt.Errorf("%s field: %w", ht.name, err)
+		}
+
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+// The beforeParse calls the BeforeParse method of the targets,
+// the parent structures first.
+func (hts hookTargets) beforeParse() []error {
+	return hts.call(false, func(obj interface{}) error {
+		if h, ok := obj.(BeforeParser); ok {
+			return h.BeforeParse()
+		}
+		return nil
+	})
+}
+
+// The setDefaults calls the SetDefaults method of the targets,
+// the parent structures first.
+func (hts hookTargets) setDefaults() {
+	hts.call(false, func(obj interface{}) error {
+		if d, ok := obj.(Defaulter); ok {
+			d.SetDefaults()
+		}
+		return nil
+	})
+}
+
+// The afterParse calls the AfterParse method of the targets,
+// the nested structures first.
+func (hts hookTargets) afterParse() []error {
+	return hts.call(true, func(obj interface{}) error {
+		if h, ok := obj.(AfterParser); ok {
+			return h.AfterParse()
+		}
+		return nil
+	})
+}
+
+// The validate calls the Validate method of the targets,
+// the nested structures first.
+func (hts hookTargets) validate() []error {
+	return hts.call(true, func(obj interface{}) error {
+		if v, ok := obj.(Validator); ok {
+			return v.Validate()
+		}
+		return nil
+	})
+}
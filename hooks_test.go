@@ -0,0 +1,142 @@
+package opt
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// The hookServer is example of the nested struct with lifecycle hooks.
+type hookServer struct {
+	Host string `opt:"host"`
+	Port int    `opt:"port" def:"80"`
+
+	calls *[]string `opt:"-"`
+}
+
+// BeforeParse implements BeforeParser interface.
+func (s *hookServer) BeforeParse() error {
+	*s.calls = append(*s.calls, "server.before")
+	return nil
+}
+
+// AfterParse implements AfterParser interface.
+func (s *hookServer) AfterParse() error {
+	*s.calls = append(*s.calls, "server.after")
+	s.Host = strings.ToLower(s.Host)
+	return nil
+}
+
+// Validate implements Validator interface.
+func (s *hookServer) Validate() error {
+	*s.calls = append(*s.calls, "server.validate")
+	if s.Port <= 0 {
+		return errors.New("port should be positive")
+	}
+	return nil
+}
+
+// The hookArgs is example of the struct with lifecycle hooks.
+type hookArgs struct {
+	Name   string `opt:"name"`
+	Server *hookServer
+
+	calls []string `opt:"-"`
+}
+
+// BeforeParse implements BeforeParser interface.
+func (a *hookArgs) BeforeParse() error {
+	a.calls = append(a.calls, "before")
+	a.Server.calls = &a.calls // the nested pointer is already allocated
+	return nil
+}
+
+// SetDefaults implements Defaulter interface.
+func (a *hookArgs) SetDefaults() {
+	a.calls = append(a.calls, "defaults")
+}
+
+// AfterParse implements AfterParser interface.
+func (a *hookArgs) AfterParse() error {
+	a.calls = append(a.calls, "after")
+	if a.Name == "" {
+		a.Name = a.Server.Host
+	}
+	return nil
+}
+
+// Validate implements Validator interface.
+func (a *hookArgs) Validate() error {
+	a.calls = append(a.calls, "validate")
+	if a.Name == "root" {
+		return errors.New("name cannot be root")
+	}
+	return nil
+}
+
+// TestHooks tests the order of the lifecycle hooks.
+func TestHooks(t *testing.T) {
+	args := hookArgs{}
+	err := Unmarshal(&args,
+		WithArgs([]string{"./app", "--server-host", "LOCALHOST"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "before,server.before,defaults,server.after,after," +
+		"server.validate,validate"
+	if v := strings.Join(args.calls, ","); v != expected {
+		t.Errorf("expected %s but %s", expected, v)
+	}
+
+	if args.Name != "localhost" || args.Server.Host != "localhost" {
+		t.Errorf("incorrect result %v %v", args, args.Server)
+	}
+}
+
+// TestHooksErrors tests the errors of the lifecycle hooks.
+func TestHooksErrors(t *testing.T) {
+	tests := []struct {
+		args   []string
+		errors []string
+		calls  string
+	}{
+		{
+			args:   []string{"./app", "--name", "root", "--server-port", "0"},
+			errors: []string{"Server field: port should be positive"},
+			calls: "before,server.before,defaults,server.after,after," +
+				"server.validate,validate",
+		},
+		{
+			args:   []string{"./app", "--name", "root"},
+			errors: []string{"name cannot be root"},
+			calls: "before,server.before,defaults,server.after,after," +
+				"server.validate,validate",
+		},
+		{
+			// The hooks aren't called for the parsing errors.
+			args:   []string{"./app", "--server-port", "http"},
+			errors: []string{"'http' is incorrect value"},
+			calls:  "before,server.before,defaults",
+		},
+	}
+
+	for i, test := range tests {
+		args := hookArgs{}
+		err := Unmarshal(&args, WithArgs(test.args))
+		if err == nil {
+			t.Errorf("%d test, expected an error", i)
+			continue
+		}
+
+		for _, e := range test.errors {
+			if !strings.Contains(err.Error(), e) {
+				t.Errorf("%d test, expected %q in %q", i, e, err)
+			}
+		}
+
+		if v := strings.Join(args.calls, ","); v != test.calls {
+			t.Errorf("%d test, expected %s but %s", i, test.calls, v)
+		}
+	}
+}
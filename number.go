@@ -0,0 +1,112 @@
+package opt
+
+import (
+	"math"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// The defBase is the default base of the integer values.
+const defBase = 10
+
+// The fileModeType is the type of os.FileMode,
+// its values are parsed in octal by default.
+var fileModeType = reflect.TypeOf(os.FileMode(0))
+
+// The typeBase returns the default base of the integer values of the t
+// type: 8 for os.FileMode and the lists of it, 10 for other types.
+func typeBase(t reflect.Type) int {
+	if scalarType(t) == fileModeType {
+		return 8
+	}
+
+	return defBase
+}
+
+// The scalarType returns the type of the values of the t type: the type
+// of the items for lists and maps, the element type for pointers.
+func scalarType(t reflect.Type) reflect.Type {
+	for !isTextUnmarshaler(t) {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+		default:
+			return t
+		}
+	}
+
+	return t
+}
+
+// The numFormat is the format of the integer values of the field.
+type numFormat struct {
+	base int    // base of the values, see strToIntKind
+	unit string // suffixes of the values: unitBytes, unitSI or empty
+}
+
+// The defFormat is the format of the decimal values without suffixes.
+var defFormat = numFormat{base: defBase}
+
+// The intFormat returns the format of the integer values of the field.
+// The base is the value of the base tag, the default base of the field
+// type if it isn't decimal (like octal for os.FileMode), 0 (Go literal
+// syntax) if the literals is true, otherwise 10. The unit is the value
+// of the unit tag.
+func (fc *fieldCast) intFormat(literals bool) numFormat {
+	nf := numFormat{base: typeBase(fc.item.Type()), unit: fc.tagGroup.unit}
+	switch {
+	case fc.tagGroup.hasBase:
+		nf.base = fc.tagGroup.base
+	case literals && nf.base == defBase:
+		nf.base = 0
+	}
+
+	return nf
+}
+
+// The trimBasePrefix removes the prefix of the base from the value,
+// like 0x for 16, so 0xff and ff are the same in the base 16.
+// The sign of the value is kept.
+func trimBasePrefix(value string, base int) string {
+	prefix := ""
+	switch base {
+	case 2:
+		prefix = "0b"
+	case 8:
+		prefix = "0o"
+	case 16:
+		prefix = "0x"
+	default:
+		return value
+	}
+
+	sign := ""
+	if value != "" && (value[0] == '-' || value[0] == '+') {
+		sign, value = value[:1], value[1:]
+	}
+
+	if len(value) > 2 && strings.EqualFold(value[:2], prefix) {
+		value = value[2:]
+	}
+
+	return sign + value
+}
+
+// The expToFloat returns the value of the number with exponent, like 1e6
+// or 2.5e3, if the base is 10 or 0 and the number is an integer. The ok is
+// false if the value isn't an integer-valued number with exponent.
+func expToFloat(value string, base int) (f float64, ok bool) {
+	if base != defBase && base != 0 || !strings.ContainsAny(value, "eE") ||
+		strings.ContainsAny(value, "xX") {
+		return 0, false
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsInf(f, 0) || f != math.Trunc(f) {
+		return 0, false
+	}
+
+	return f, true
+}
@@ -0,0 +1,143 @@
+package opt
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+// TestStrToIntKindBase tests strToIntKind function with bases.
+func TestStrToIntKindBase(t *testing.T) {
+	tests := []struct {
+		value    string
+		base     int
+		expected int64
+		err      bool
+	}{
+		{"0xff", 0, 255, false},
+		{"-0x10", 0, -16, false},
+		{"0755", 0, 493, false},
+		{"0o755", 0, 493, false},
+		{"0b101", 0, 5, false},
+		{"1_000_000", 0, 1000000, false},
+		{"1e6", 0, 1000000, false},
+		{"1e6", 10, 1000000, false},
+		{"2.5e3", 10, 2500, false},
+		{"-1E2", 10, -100, false},
+		{"ff", 16, 255, false},
+		{"0xFF", 16, 255, false},
+		{"755", 8, 493, false},
+		{"0o755", 8, 493, false},
+		{"0xff", 10, 0, true},
+		{"1_000", 10, 0, true},
+		{"1.5e0", 10, 0, true},
+		{"1e6", 16, 0x1e6, false},
+		{"1e6", 8, 0, true},
+		{"1e19", 10, 0, true},
+	}
+
+	for i, test := range tests {
+		r, err := strToIntKind(test.value, reflect.Int64, test.base)
+		switch {
+		case test.err && err == nil:
+			t.Errorf("%d test, expected an error for %s", i, test.value)
+		case !test.err && err != nil:
+			t.Errorf("%d test, %v", i, err)
+		case r != test.expected:
+			t.Errorf("%d test, expected %d but %d", i, test.expected, r)
+		}
+	}
+
+	// The overflow of the target kind.
+	if _, err := strToIntKind("1e3", reflect.Int8, 10); err == nil {
+		t.Error("expected an error for int8 overflow")
+	}
+}
+
+// TestStrToUintKindBase tests strToUintKind function with bases.
+func TestStrToUintKindBase(t *testing.T) {
+	tests := []struct {
+		value    string
+		base     int
+		expected uint64
+		err      bool
+	}{
+		{"0xff", 0, 255, false},
+		{"0b1111_0000", 0, 240, false},
+		{"1e19", 10, 1e19, false},
+		{"-1e3", 10, 0, true},
+		{"1e20", 10, 0, true},
+	}
+
+	for i, test := range tests {
+		r, err := strToUintKind(test.value, reflect.Uint64, test.base)
+		switch {
+		case test.err && err == nil:
+			t.Errorf("%d test, expected an error for %s", i, test.value)
+		case !test.err && err != nil:
+			t.Errorf("%d test, %v", i, err)
+		case r != test.expected:
+			t.Errorf("%d test, expected %d but %d", i, test.expected, r)
+		}
+	}
+}
+
+// TestBaseTag tests base tag and WithNumberLiterals option.
+func TestBaseTag(t *testing.T) {
+	type data struct {
+		Mask  uint8             `opt:"m" base:"16"`
+		Mode  os.FileMode       `opt:"M" def:"644"`
+		Modes map[string]uint32 `opt:"x" base:"8"`
+		Count int               `opt:"c"`
+		Port  int               `opt:"p" base:"10"`
+	}
+
+	args := data{}
+	errs := unmarshalOpt(&args, []string{"./app", "-m", "ff", "-x", "a=755",
+		"-c", "1e6", "-p", "080"})
+	if len(errs) != 0 {
+		t.Fatal(errs)
+	}
+
+	expected := data{Mask: 255, Mode: 0644, Modes: map[string]uint32{
+		"a": 0755}, Count: 1000000, Port: 80}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected %v but %v", expected, args)
+	}
+
+	// The Go literal syntax for all fields, except the fields
+	// with the base tag and os.FileMode.
+	args = data{}
+	errs = unmarshalOpt(&args, []string{"./app", "-c", "0x1_0", "-p", "080",
+		"-M", "0o755"}, WithNumberLiterals())
+	if len(errs) != 0 {
+		t.Fatal(errs)
+	}
+
+	if args.Count != 16 || args.Port != 80 || args.Mode != 0755 {
+		t.Errorf("expected 16, 80 and 0755 but %d, %d and %o",
+			args.Count, args.Port, args.Mode)
+	}
+
+	// Without the option, the value is decimal.
+	errs = unmarshalOpt(&args, []string{"./app", "-c", "0x10"})
+	if len(errs) != 1 {
+		t.Errorf("expected one error but %v", errs)
+	}
+
+	for _, obj := range []interface{}{
+		&struct {
+			Name string `base:"16"`
+		}{},
+		&struct {
+			Count int `base:"1"`
+		}{},
+		&struct {
+			Count int `base:"37"`
+		}{},
+	} {
+		if err := Check(obj); err == nil {
+			t.Errorf("expected an error for %T", obj)
+		}
+	}
+}
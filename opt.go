@@ -1,7 +1,10 @@
 package opt
 
 import (
+	"fmt"
 	"os"
+	"reflect"
+	"runtime/debug"
 )
 
 // Unmarshal parses the argument-line options and stores the result
@@ -11,18 +14,24 @@ import (
 // Unmarshal method supports the following field's types: int, int8, int16,
 // int32, int64, uin, uint8, uin16, uint32, in64, float32, float64, string,
 // bool, url.URL and pointers, array or slice from thous types (i.e. *int, ...,
-// []int, ..., []bool, ..., [2]*url.URL, etc.).
+// []int, ..., []bool, ..., [2]*url.URL, etc.), and maps with string keys
+// and values of thous types (i.e. map[string]string, map[string]int, etc.).
+// The lists of lists, like [][]string or [][2]int, are supported too
+// (see sep2 tag).
+// The nested structures (and pointers to them) are parsed as groups of
+// options, the long flags of their fields get the prefix (see prefix tag).
 //
-// For other filed's types (like chan, map ...) will be returned an error.
+// For other filed's types (like chan, func ...) will be returned an error.
 //
 // The function generates a panic if:
 //
 // - the object isn't a structure;
 // - the object isn't transmitted by pointer;
-// - a non-string type field is specified for the `opt:"?"` doc-field;
+// - the `opt:"?"` doc-field isn't a string or func() string;
 // - field for positional arguments `opt:"[]"` is not a list (slice/array);
-// - field has structure type (except url.URL);
-// - field has pointer to structure type (except *url.URL).
+// - field has empty structure type or pointer to empty structure type;
+// - nested structure contains itself at any depth;
+// - some flag name is declared more than once.
 //
 // Use the following tags in the fields of structure to
 // set the marshing parameters:
@@ -33,6 +42,28 @@ import (
 //	     can be specified in alt or vice versa;
 //	def  default value (if empty, sets the default value
 //	     for the field type of structure);
+//	expand if true, expands ${VAR} and ${VAR:-fallback}
+//	     environment variables in the default value;
+//	sep  list delimiter for slice, array and map fields;
+//	sep2 inner list delimiter for the list of lists fields,
+//	     like [][]string or [][2]int;
+//	list mode of slice fields, comma-separated: replace (by
+//	     default) or append, unique, sort;
+//	split rules of splitting by sep, comma-separated: csv (quoted
+//	     items) or escape (backslash escapes), trim, noempty;
+//	kvsep key/value delimiter for map fields (= by default);
+//	dup  duplicate key policy for map fields: last (by default),
+//	     first or error;
+//	base base of the integer values: 2..36 or 0 for the Go
+//	     literal syntax (0xff, 0755, 1_000), 10 by default and
+//	     8 for os.FileMode;
+//	unit suffixes of the integer values: bytes (512K, 10MiB,
+//	     1.5GB) or si (10k, 2.5M), see ByteSize;
+//	strict if true, the repeated flag of the non-list field
+//	     is an error;
+//	prefix prefix for the long flags of the nested structure fields
+//	     (the field name in kebab case by default), an empty value
+//	     flattens the nested structure;
 //	help brief help about the option.
 //
 // Suppose that the some values was set into argument-line as:
@@ -59,11 +90,321 @@ import (
 //	// Output:
 //	//  Host: 0.0.0.0
 //	//  Port: 8080
-func Unmarshal(obj interface{}) error {
-	if errs := unmarshalOpt(obj, os.Args); errs != nil {
+//
+// The opts customize the parsing, for example, WithArgs sets the
+// command line arguments instead of os.Args.
+func Unmarshal(obj interface{}, opts ...Option) error {
+	cfg := newConfig(opts...)
+	if errs := decodeOpt(obj, cfg.args, cfg); errs != nil {
 		// Returns only the first error.
 		return errs[0]
 	}
 
 	return nil
 }
+
+// ParseKnown parses the known command-line options into the obj structure,
+// like Unmarshal, but doesn't return an error for the unknown flags. It
+// returns the arguments which aren't consumed: the unknown flags and the
+// positional arguments which don't have the fields, in the order of the
+// command line, for example, to forward them to a child program:
+//
+//	// ./launcher -v --timeout=5 -x run
+//	rest, err := opt.ParseKnown(&args) // -v is known
+//	// rest: [--timeout=5 -x run]
+//
+// The unknown long flag is kept together with its value: the value after
+// the = symbol or the next argument which doesn't start with a dash, like
+// --timeout 7 (except the --no- flags, which are boolean). The value of
+// the unknown short flag is kept with it if it's passed after the = symbol
+// only, otherwise it's a positional argument. In the short flag group, the known letters are the flags until the first
+// unknown character: the non-letter character starts the value of the last
+// known flag (-p8080). The letters after the boolean flag are kept as the
+// group of unknown flags (-vxz gives v flag and -xz is kept), the letters
+// after the flag of other type are its value (-UGoloop).
+//
+// The same arguments are set into the `opt:"..."` field of the []string
+// type, its presence enables this mode for Unmarshal too.
+func ParseKnown(obj interface{}, opts ...Option) ([]string, error) {
+	cfg := newConfig(opts...)
+	cfg.known = true
+	if errs := decodeOpt(obj, cfg.args, cfg); errs != nil {
+		// Returns only the first error.
+		return cfg.rest, errs[0]
+	}
+
+	return cfg.rest, nil
+}
+
+// UnmarshalString parses the options from the line, like a command line,
+// and stores the result to go-struct, the same as Unmarshal. The line is
+// split into arguments by the Split function, for example:
+//
+//	err := opt.UnmarshalString(&args, os.Getenv("APP_OPTS"))
+//
+// The line doesn't contain the path to the application, the zero
+// positional argument is taken from os.Args. The WithArgs option
+// is ignored.
+func UnmarshalString(obj interface{}, line string, opts ...Option) error {
+	words, err := Split(line)
+	if err != nil {
+		return err
+	}
+
+	cfg := newConfig(opts...)
+	args := make([]string, 0, len(words)+1)
+	if len(os.Args) != 0 {
+		args = append(args, os.Args[0])
+	} else {
+		args = append(args, "")
+	}
+	args = append(args, words...)
+
+	if errs := decodeOpt(obj, args, cfg); errs != nil {
+		// Returns only the first error.
+		return errs[0]
+	}
+
+	return nil
+}
+
+// Check validates the definition of the obj structure and returns all
+// problems found as the Errors list, or nil if there are no problems.
+// The obj can be a structure or a pointer to it, it is not changed.
+//
+// Unlike Unmarshal, which panics on the first problem of the structure,
+// Check doesn't panic and reports: invalid tags, conflicting flag names,
+// unsupported field types and default values that cannot be converted
+// to the field type. It's convenient to call it in unit tests:
+//
+//	func TestArgs(t *testing.T) {
+//		if err := opt.Check(&Args{}); err != nil {
+//			t.Error(err)
+//		}
+//	}
+func Check(obj interface{}) error {
+	// Check a new instance of the structure, so as not
+	// to change the obj (the nested pointers are allocated).
+	if rt := reflect.TypeOf(obj); rt != nil {
+		if rt.Kind() == reflect.Ptr {
+			rt = rt.Elem()
+		}
+
+		if rt.Kind() == reflect.Struct {
+			obj = reflect.New(rt).Interface()
+		}
+	}
+
+	var errs Errors
+	fcl, err := getFieldCastList(obj)
+	if list, ok := err.(Errors); ok {
+		errs = append(errs, list...)
+	} else if err != nil {
+		return Errors{err}
+	}
+
+	for _, fc := range fcl {
+		if !fc.item.CanSet() {
+			errs = append(errs,
+				fmt.Errorf("%s field cannot be set", fc.fieldName))
+			continue
+		}
+
+		// The types of the special fields, except the positional
+		// arguments, are checked by getFieldCastList.
+		if f := fc.tagGroup.shortFlag; isSpecialFlag(f) &&
+			!orderFlagRgx.MatchString(f) {
+			continue
+		}
+
+		if !isSupported(fc.item.Type()) {
+			errs = append(errs,
+				fmt.Errorf("%s field has invalid type", fc.fieldName))
+			continue
+		}
+
+		// Try to set the default value into a new
+		// instance of the field type.
+		item := reflect.New(fc.item.Type()).Elem()
+
+		tmp := fieldCast{fieldName: fc.fieldName, tagGroup: fc.tagGroup,
+			item: &item}
+		err := tmp.setValues([]string{fc.tagGroup.defaultValue()}, false,
+			tmp.intFormat(false))
+		if err != nil {
+			errs = append(errs, fmt.Errorf(
+				"%s field has invalid default value: %v",
+				fc.fieldName, err,
+			))
+		}
+	}
+
+	if len(errs) != 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// Help returns the help information about the command-line options of
+// the obj structure, the same as it's set into the `opt:"?"` doc-field.
+// The obj can be a structure or a pointer to it, it is not changed.
+//
+// Unlike the doc-field of the string type, which is generated on each
+// call of the Unmarshal, the Help generates the text on demand only.
+// The doc-field of the func() string type can be used for the same goal:
+//
+//	type Args struct {
+//		Help bool          `opt:"h" alt:"help" help:"show help"`
+//		Doc  func() string `opt:"?"`
+//	}
+//
+//	if args.Help {
+//		fmt.Println(args.Doc())
+//	}
+//
+// The function generates a panic if the obj structure has problems in
+// the field definitions, the same as Unmarshal function.
+func Help(obj interface{}) string {
+	rt := reflect.TypeOf(obj)
+	if rt != nil && rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+
+	if rt == nil || rt.Kind() != reflect.Struct {
+		panic("obj should be a struct or a pointer to a struct")
+	}
+
+	return getStructHelp(rt, nil)
+}
+
+// The getStructHelp returns help information about the command-line
+// options of the rt structure type and the extra options.
+func getStructHelp(rt reflect.Type, extra fieldCastList) string {
+	info := getStructInfo(rt)
+	if info.err != nil {
+		panic(info.err)
+	}
+
+	fcl := info.fields.bind(reflect.New(rt).Elem())
+	return getHelp(append(fcl, extra...), argMap{})
+}
+
+// ParseOrExit parses the command-line options into the obj structure,
+// like Unmarshal, and handles the built-in options:
+//
+//	-h, --help    prints the help information and exits with code 0;
+//	    --version prints the version and exits with code 0 (it's
+//	              available if the WithVersion option is set).
+//
+// The built-in flag isn't added if the structure declares the flag with
+// the same name, so the -h flag can be used for something else, like a
+// host name, and only --help is added.
+//
+// If the command line has errors, it prints them with the help
+// information and exits with code 2.
+//
+// Use the WithOutput and WithErrOutput options to set writers for the
+// help and for the errors, and the WithExit option to replace os.Exit,
+// for example, in tests:
+//
+//	type Args struct {
+//		Host string `opt:"H" alt:"host" help:"host of the server"`
+//		Port int    `opt:"p" alt:"port" help:"port of the server"`
+//	}
+//
+//	var args Args
+//	opt.ParseOrExit(&args, opt.WithVersion("v1.0.0"))
+func ParseOrExit(obj interface{}, opts ...Option) {
+	cfg := newConfig(opts...)
+
+	// Add built-in options which the structure doesn't declare.
+	rt, _, err := validateStruct(obj)
+	if err != nil {
+		panic(err)
+	}
+
+	info := getStructInfo(rt.Elem())
+	cfg.builtin = getBuiltinOptions(info.flags, cfg)
+
+	errs := decodeOpt(obj, cfg.args, cfg)
+	switch {
+	case cfg.help:
+		fmt.Fprintln(cfg.output, getStructHelp(rt.Elem(), cfg.builtin))
+		cfg.exit(0)
+	case cfg.showVersion:
+		version := *cfg.version
+		if version == "" {
+			version = getBuildVersion()
+		}
+
+		fmt.Fprintln(cfg.output, version)
+		cfg.exit(0)
+	case len(errs) != 0:
+		for _, err := range errs {
+			fmt.Fprintf(cfg.errOutput, "Error: %v\n", err)
+		}
+
+		if help := getStructHelp(rt.Elem(), cfg.builtin); help != "" {
+			fmt.Fprintf(cfg.errOutput, "\n%s\n", help)
+		}
+
+		cfg.exit(2)
+	}
+}
+
+// The getBuiltinOptions returns the built-in options, which are not
+// declared in the flags, with the fields bound to the config values.
+func getBuiltinOptions(flags map[string]int, cfg *config) fieldCastList {
+	var result fieldCastList
+
+	// The --help option.
+	short, long := "h", "help"
+	if _, ok := flags[short]; ok {
+		short = ""
+	}
+
+	if _, ok := flags[long]; ok {
+		long = ""
+	}
+
+	if short != "" || long != "" {
+		item := reflect.ValueOf(&cfg.help).Elem()
+		result = append(result, &fieldCast{
+			fieldName: "help",
+			tagGroup: &tagGroup{
+				shortFlag: short,
+				longFlag:  long,
+				helpMsg:   "show help information and exit",
+				isBool:    true,
+			},
+			item: &item,
+		})
+	}
+
+	// The --version option.
+	if _, ok := flags["version"]; !ok && cfg.version != nil {
+		item := reflect.ValueOf(&cfg.showVersion).Elem()
+		result = append(result, &fieldCast{
+			fieldName: "version",
+			tagGroup: &tagGroup{
+				longFlag: "version",
+				helpMsg:  "show version information and exit",
+				isBool:   true,
+			},
+			item: &item,
+		})
+	}
+
+	return result
+}
+
+// The getBuildVersion returns the version of the main module
+// from the build information.
+func getBuildVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+
+	return "(devel)"
+}
@@ -1,6 +1,7 @@
 package opt
 
 import (
+	"bytes"
 	"os"
 	"strings"
 	"testing"
@@ -138,3 +139,490 @@ func TestUnmarshalErrors(t *testing.T) {
 		t.Error("expected an error")
 	}
 }
+
+// TestCheck tests Check function.
+func TestCheck(t *testing.T) {
+	type server struct {
+		Host string // --server-host
+	}
+
+	type correct struct {
+		Host   string            `opt:"host" alt:"H" def:"localhost"`
+		Port   int               `opt:"p" alt:"port" def:"8080"`
+		Users  []string          `opt:"U" def:"John,Bob" sep:","`
+		Labels map[string]string `def:"a=b"`
+		Server *server
+		Doc    string `opt:"?"`
+	}
+
+	type wrong struct {
+		Host   string   `opt:"server-host"`    // conflicts with Server.Host
+		Port   int      `def:"http"`           // invalid default value
+		User   string   `opt:"user" alt:"***"` // invalid tag
+		Ch     chan int // unsupported type
+		Arr    [1]int   `def:"1,2" sep:","` // overflow of default value
+		Doc    int      `opt:"?"`           // should be a string
+		Server server
+	}
+
+	if err := Check(&correct{}); err != nil {
+		t.Error(err)
+	}
+
+	// The lazy doc-field.
+	lazy := struct {
+		Host string
+		Doc  func() string `opt:"?"`
+	}{}
+	if err := Check(&lazy); err != nil {
+		t.Error(err)
+	}
+
+	if err := Check(correct{}); err != nil {
+		t.Error(err)
+	}
+
+	obj := &correct{}
+	if err := Check(obj); err != nil || obj.Server != nil {
+		t.Errorf("expected unchanged object without error but %v", err)
+	}
+
+	err := Check(&wrong{})
+	errs, ok := err.(Errors)
+	if !ok {
+		t.Fatalf("expected Errors but %T", err)
+	}
+
+	if len(errs) != 6 {
+		t.Errorf("expected 6 errors but %d:\n%v", len(errs), errs)
+	}
+
+	if err := Check(nil); err == nil {
+		t.Error("expected an error for nil")
+	}
+
+	if err := Check(new(int)); err == nil {
+		t.Error("expected an error for non-struct object")
+	}
+}
+
+// TestHelp tests Help function and the lazy doc-field.
+func TestHelp(t *testing.T) {
+	type data struct {
+		Host string        `opt:"host" help:"host of the server"`
+		Port int           `opt:"p" alt:"port" help:"port of the server"`
+		Doc  func() string `opt:"?"`
+		Text string        `opt:"?"`
+	}
+
+	exp := "Options:\n" +
+		"        --host host of the server;\n" +
+		"    -p, --port port of the server."
+
+	if v := Help(data{}); v != exp {
+		t.Errorf("expected %q but %q", exp, v)
+	}
+
+	if v := Help(&data{}); v != exp {
+		t.Errorf("expected %q but %q", exp, v)
+	}
+
+	os.Args = []string{"./app", "-p", "80"}
+	args := data{}
+	if err := Unmarshal(&args); err != nil {
+		t.Error(err)
+	}
+
+	if args.Doc == nil {
+		t.Fatal("expected help function")
+	}
+
+	if v := args.Doc(); v != exp || v != args.Text {
+		t.Errorf("expected %q but %q", exp, v)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("expected panic for non-struct object")
+		}
+	}()
+	Help(new(int))
+}
+
+// TestParseOrExit tests ParseOrExit function.
+func TestParseOrExit(t *testing.T) {
+	type data struct {
+		Host string `opt:"h" alt:"host" help:"host of the server"`
+		Port int    `opt:"p" alt:"port" help:"port of the server"`
+	}
+
+	tests := []struct {
+		args   []string
+		code   int
+		output string
+		errors string
+	}{
+		{
+			args: []string{"./app", "-h", "localhost"},
+			code: -1,
+		},
+		{
+			args: []string{"./app", "--help"},
+			code: 0,
+			output: "Options:\n" +
+				"        --help    show help information and exit;\n" +
+				"    -h, --host    host of the server;\n" +
+				"    -p, --port    port of the server;\n" +
+				"        --version show version information and exit.\n",
+		},
+		{
+			args:   []string{"./app", "--version"},
+			code:   0,
+			output: "v1.2.3\n",
+		},
+		{
+			args:   []string{"./app", "--port", "http"},
+			code:   2,
+			errors: "Error: 'http' is incorrect value\n\nOptions:\n",
+		},
+	}
+
+	for i, test := range tests {
+		var (
+			args           data
+			output, errors bytes.Buffer
+		)
+
+		code := -1
+		ParseOrExit(&args,
+			WithArgs(test.args),
+			WithVersion("v1.2.3"),
+			WithOutput(&output),
+			WithErrOutput(&errors),
+			WithExit(func(c int) { code = c }),
+		)
+
+		if code != test.code {
+			t.Errorf("%d test, expected code %d but %d", i, test.code, code)
+		}
+
+		if v := output.String(); v != test.output {
+			t.Errorf("%d test, expected output %q but %q",
+				i, test.output, v)
+		}
+
+		if v := errors.String(); !strings.HasPrefix(v, test.errors) {
+			t.Errorf("%d test, expected errors %q but %q",
+				i, test.errors, v)
+		}
+	}
+}
+
+// TestUnmarshalString tests UnmarshalString function.
+func TestUnmarshalString(t *testing.T) {
+	type data struct {
+		Debug bool   `opt:"debug"`
+		Port  int    `opt:"p"`
+		Name  string `opt:"name"`
+		Pos   []int  `opt:"[]"`
+	}
+
+	args := data{}
+	line := "--debug -p 8080 --name 'John Doe' -- 1 2"
+	if err := UnmarshalString(&args, line); err != nil {
+		t.Error(err)
+	}
+
+	if !args.Debug || args.Port != 8080 || args.Name != "John Doe" ||
+		len(args.Pos) != 2 {
+		t.Errorf("incorrect result %v", args)
+	}
+
+	if err := UnmarshalString(&args, "--name 'John"); err == nil {
+		t.Error("expected an error for unterminated quote")
+	}
+
+	if err := UnmarshalString(&args, "--user John"); err == nil {
+		t.Error("expected an error for unknown flag")
+	}
+}
+
+// TestParseKnown tests ParseKnown function and the field for the rest.
+func TestParseKnown(t *testing.T) {
+	type data struct {
+		Verbose bool   `opt:"v" alt:"verbose"`
+		Port    int    `opt:"p" alt:"port"`
+		Name    string `opt:"1"`
+	}
+
+	tests := []struct {
+		args []string
+		rest []string
+	}{
+		{
+			[]string{"./app", "-v", "--timeout=5", "-x", "run", "cmd"},
+			[]string{"--timeout=5", "-x", "cmd"},
+		},
+		{
+			[]string{"./app", "-vxz", "-p8080", "run", "--", "--help"},
+			[]string{"-xz", "--help"},
+		},
+		{
+			[]string{"./app", "-p", "80", "--no-cache", "run"},
+			[]string{"--no-cache"},
+		},
+		{
+			[]string{"./app", "-vp", "80"},
+			[]string{},
+		},
+	}
+
+	for i, test := range tests {
+		args := data{}
+		rest, err := ParseKnown(&args, WithArgs(test.args))
+		if err != nil {
+			t.Errorf("%d test, %v", i, err)
+			continue
+		}
+
+		if strings.Join(rest, " ") != strings.Join(test.rest, " ") {
+			t.Errorf("%d test, expected %q but %q", i, test.rest, rest)
+		}
+	}
+
+	args := data{}
+	rest, err := ParseKnown(&args,
+		WithArgs([]string{"./app", "-vp", "80", "run", "-x"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !args.Verbose || args.Port != 80 || args.Name != "run" ||
+		len(rest) != 1 {
+		t.Errorf("incorrect result %v, %q", args, rest)
+	}
+
+	// The field for the rest enables the known mode for Unmarshal.
+	type launcher struct {
+		Verbose bool     `opt:"v"`
+		Rest    []string `opt:"..."`
+		Pos     []string `opt:"[]"`
+	}
+
+	obj := launcher{Rest: []string{"old"}}
+	test := []string{"./app", "-v", "--color=auto", "-l", "a", "b"}
+	if err := Unmarshal(&obj, WithArgs(test)); err != nil {
+		t.Fatal(err)
+	}
+
+	if !obj.Verbose || strings.Join(obj.Rest, " ") != "--color=auto -l" ||
+		len(obj.Pos) != 2 {
+		t.Errorf("incorrect result %v", obj)
+	}
+
+	// The unknown long flag keeps its value, it isn't positional.
+	obj = launcher{}
+	test = []string{"./app", "-v", "--timeout", "7", "run", "--no-color", "x"}
+	if err := Unmarshal(&obj, WithArgs(test)); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(obj.Rest, " ") != "--timeout 7 --no-color" ||
+		strings.Join(obj.Pos, " ") != "run x" {
+		t.Errorf("incorrect result %v", obj)
+	}
+
+	// The letters after the known flag with the value are its value,
+	// the letters after the known boolean flag are the unknown flags.
+	type user struct {
+		Verbose bool     `opt:"v"`
+		User    string   `opt:"U"`
+		Rest    []string `opt:"..."`
+	}
+
+	usr := user{}
+	test = []string{"./app", "-UGoloop", "-vx", "-vUBob"}
+	if err := Unmarshal(&usr, WithArgs(test)); err != nil {
+		t.Fatal(err)
+	}
+
+	if !usr.Verbose || usr.User != "Bob" ||
+		strings.Join(usr.Rest, " ") != "-x" {
+		t.Errorf("incorrect result %v", usr)
+	}
+
+	usr = user{}
+	test = []string{"./app", "-UGoloop"}
+	if err := Unmarshal(&usr, WithArgs(test)); err != nil {
+		t.Fatal(err)
+	}
+
+	if usr.User != "Goloop" || len(usr.Rest) != 0 {
+		t.Errorf("incorrect result %v", usr)
+	}
+
+	wrong := struct {
+		Rest []int `opt:"..."`
+	}{}
+
+	if err := Check(&wrong); err == nil {
+		t.Error("expected an error for non-[]string field")
+	}
+}
+
+// TestStopAtPositional tests the stop at the first positional argument
+// and the field for the raw arguments.
+func TestStopAtPositional(t *testing.T) {
+	type data struct {
+		Verbose bool     `opt:"v" alt:"verbose"`
+		Dir     string   `opt:"C"`
+		Cmd     string   `opt:"1"`
+		Raw     []string `opt:"--"`
+	}
+
+	tests := []struct {
+		args []string
+		opts []Option
+		cmd  string
+		raw  []string
+	}{
+		{
+			args: []string{"./app", "-C", "/tmp", "ls", "-la", "--", "-v"},
+			opts: []Option{WithStopAtPositional()},
+			cmd:  "ls",
+			raw:  []string{"ls", "-la", "--", "-v"},
+		},
+		{
+			args: []string{"./app", "-v", "exec", "ls", "-la"},
+			opts: []Option{WithStopAtPositional()},
+			cmd:  "exec",
+			raw:  []string{"exec", "ls", "-la"},
+		},
+		{
+			args: []string{"./app", "--verbose", "exec", "ls"},
+			opts: []Option{WithStopAtPositional()},
+			cmd:  "exec",
+			raw:  []string{"exec", "ls"},
+		},
+		{
+			args: []string{"./app", "-C", "/tmp", "--", "ls", "-v"},
+			cmd:  "ls",
+			raw:  []string{"ls", "-v"},
+		},
+		{
+			args: []string{"./app", "-C", "/tmp", "ls"},
+			cmd:  "ls",
+			raw:  nil,
+		},
+	}
+
+	for i, test := range tests {
+		args := data{}
+		opts := append(test.opts, WithArgs(test.args))
+		if err := Unmarshal(&args, opts...); err != nil {
+			t.Errorf("%d test, %v", i, err)
+			continue
+		}
+
+		if args.Cmd != test.cmd {
+			t.Errorf("%d test, expected %s but %s", i, test.cmd, args.Cmd)
+		}
+
+		if strings.Join(args.Raw, " ") != strings.Join(test.raw, " ") {
+			t.Errorf("%d test, expected %q but %q", i, test.raw, args.Raw)
+		}
+	}
+
+	// The -- after the stop point is kept in the positional arguments.
+	type command struct {
+		Verbose bool     `opt:"v"`
+		Pos     []string `opt:"[]"`
+		Raw     []string `opt:"--"`
+	}
+
+	for _, test := range [][]string{
+		{"./app", "-v", "exec", "ls", "-l", "--", "x"},
+		{"./app", "-v", "--", "exec", "ls", "-l", "--", "x"},
+	} {
+		cmd := command{}
+		err := Unmarshal(&cmd, WithArgs(test), WithStopAtPositional())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := "exec ls -l -- x"
+		if !cmd.Verbose || strings.Join(cmd.Pos, " ") != expected ||
+			strings.Join(cmd.Raw, " ") != expected {
+			t.Errorf("expected %q but %q and %q", expected, cmd.Pos, cmd.Raw)
+		}
+	}
+
+	// Without the stop, the flags after the positional are parsed.
+	args := data{}
+	test := []string{"./app", "ls", "-v"}
+	if err := Unmarshal(&args, WithArgs(test)); err != nil || !args.Verbose {
+		t.Errorf("expected verbose mode but %v (%v)", args, err)
+	}
+
+	// The POSIXLY_CORRECT environment variable.
+	t.Setenv("POSIXLY_CORRECT", "1")
+	args = data{}
+	err := Unmarshal(&args, WithArgs(test), WithPosixlyCorrect())
+	if err != nil || args.Verbose || len(args.Raw) != 2 {
+		t.Errorf("expected stop at ls but %v (%v)", args, err)
+	}
+}
+
+// TestShortEquals tests the -p=value form of the short flags.
+func TestShortEquals(t *testing.T) {
+	type data struct {
+		Verbose bool   `opt:"v"`
+		Port    int    `opt:"p" def:"80"`
+		Expr    string `opt:"e"`
+	}
+
+	tests := []struct {
+		args     []string
+		opts     []Option
+		expected data
+	}{
+		{
+			args:     []string{"./app", "-p=8080", "-e=x=1"},
+			expected: data{Port: 8080, Expr: "x=1"},
+		},
+		{
+			args:     []string{"./app", "-vp=8080", "-e="},
+			expected: data{Verbose: true, Port: 8080},
+		},
+		{
+			args:     []string{"./app", "-p=", "-e", "=x"},
+			expected: data{Expr: "=x"},
+		},
+		{
+			args:     []string{"./app", "-p8080", "-e=x=1"},
+			opts:     []Option{WithoutShortEquals()},
+			expected: data{Port: 8080, Expr: "=x=1"},
+		},
+	}
+
+	for i, test := range tests {
+		args := data{}
+		opts := append(test.opts, WithArgs(test.args))
+		if err := Unmarshal(&args, opts...); err != nil {
+			t.Errorf("%d test, %v", i, err)
+			continue
+		}
+
+		if args != test.expected {
+			t.Errorf("%d test, expected %v but %v", i, test.expected, args)
+		}
+	}
+
+	// Without the = form, the = sign is a part of the value.
+	args := data{}
+	err := Unmarshal(&args, WithoutShortEquals(),
+		WithArgs([]string{"./app", "-p=8080"}))
+	if err == nil {
+		t.Error("expected an error for =8080 value")
+	}
+}
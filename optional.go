@@ -0,0 +1,87 @@
+package opt
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Optional is a value of the option that records whether the option is
+// provided: passed on the command line or set by the def tag. Unlike the
+// pointer field, it doesn't require dereferencing, and the presence is
+// recorded even when the zero value is passed explicitly, for example:
+// --retries 0.
+//
+// The T can be any type supported for the fields of the structure:
+// numbers, strings, booleans, url.URL and encoding.TextUnmarshaler.
+//
+// Example usage:
+//
+//	var args struct {
+//	    Retries opt.Optional[int] `opt:"retries" help:"number of retries"`
+//	}
+//
+//	retries := args.Retries.OrElse(3)
+type Optional[T any] struct {
+	value T
+	set   bool
+}
+
+// Some returns the Optional with the value which is set.
+func Some[T any](value T) Optional[T] {
+	return Optional[T]{value: value, set: true}
+}
+
+// Get returns the value and true if the value is set,
+// or the zero value and false otherwise.
+func (o Optional[T]) Get() (T, bool) {
+	return o.value, o.set
+}
+
+// OrElse returns the value if it's set, or def otherwise.
+func (o Optional[T]) OrElse(def T) T {
+	if o.set {
+		return o.value
+	}
+
+	return def
+}
+
+// IsSet returns true if the value is set.
+func (o Optional[T]) IsSet() bool {
+	return o.set
+}
+
+// String returns the value as string, or empty string if it isn't set.
+func (o Optional[T]) String() string {
+	if !o.set {
+		return ""
+	}
+
+	return fmt.Sprint(o.value)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+// The value which isn't set is marshaled as empty text.
+func (o Optional[T]) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// The text is converted by the same rules as the value of the field
+// of the T type, and the value is marked as set.
+func (o *Optional[T]) UnmarshalText(text []byte) error {
+	var value T
+	err := setValue(reflect.ValueOf(&value).Elem(), string(text))
+	if err != nil {
+		return err
+	}
+
+	o.value, o.set = value, true
+	return nil
+}
+
+// The valueType returns the type of the value, it's used to check
+// whether the T is supported by the parser.
+func (o *Optional[T]) valueType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
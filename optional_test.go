@@ -0,0 +1,145 @@
+package opt
+
+import (
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// TestOptional tests the methods of the Optional.
+func TestOptional(t *testing.T) {
+	var o Optional[int]
+	if v, ok := o.Get(); ok || v != 0 {
+		t.Errorf("expected unset value but %d, %v", v, ok)
+	}
+
+	if o.IsSet() || o.OrElse(5) != 5 || o.String() != "" {
+		t.Errorf("expected unset value but %v", o)
+	}
+
+	o = Some(0)
+	if v, ok := o.Get(); !ok || v != 0 {
+		t.Errorf("expected set value but %d, %v", v, ok)
+	}
+
+	if !o.IsSet() || o.OrElse(5) != 0 || o.String() != "0" {
+		t.Errorf("expected set value but %v", o)
+	}
+
+	if err := o.UnmarshalText([]byte("seven")); err == nil {
+		t.Error("expected an error for incorrect value")
+	}
+
+	if err := o.UnmarshalText([]byte("7")); err != nil || o.OrElse(0) != 7 {
+		t.Errorf("expected 7 but %v (%v)", o, err)
+	}
+
+	if v, err := o.MarshalText(); err != nil || string(v) != "7" {
+		t.Errorf("expected 7 but %s (%v)", v, err)
+	}
+}
+
+// TestUnmarshalOptional tests unmarshalOpt with Optional fields.
+func TestUnmarshalOptional(t *testing.T) {
+	type data struct {
+		Retries Optional[int]     `opt:"retries"`
+		Debug   Optional[bool]    `opt:"d"`
+		Name    Optional[string]  `opt:"name" def:"guest"`
+		Site    Optional[url.URL] `opt:"site"`
+		Ratio   Optional[float64] `opt:"ratio"`
+		IP      net.IP            `opt:"ip"`
+		Hosts   []Optional[int]   `opt:"H" sep:","`
+	}
+
+	obj := data{}
+	test := []string{"./app", "--retries", "0", "-d", "--ip", "127.0.0.1",
+		"-H", "1,2"}
+	if err := unmarshalOpt(&obj, test); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, ok := obj.Retries.Get(); !ok || v != 0 {
+		t.Errorf("expected explicit 0 but %d, %v", v, ok)
+	}
+
+	if v, ok := obj.Debug.Get(); !ok || !v {
+		t.Errorf("expected true but %v, %v", v, ok)
+	}
+
+	if v, ok := obj.Name.Get(); !ok || v != "guest" {
+		t.Errorf("expected default value but %q, %v", v, ok)
+	}
+
+	if obj.Site.IsSet() || obj.Ratio.IsSet() {
+		t.Errorf("expected unset values but %v, %v", obj.Site, obj.Ratio)
+	}
+
+	if !obj.IP.Equal(net.IPv4(127, 0, 0, 1)) {
+		t.Errorf("expected 127.0.0.1 but %v", obj.IP)
+	}
+
+	if len(obj.Hosts) != 2 || obj.Hosts[1].OrElse(0) != 2 {
+		t.Errorf("expected [1 2] but %v", obj.Hosts)
+	}
+
+	test = []string{"./app", "--retries", "many"}
+	if err := unmarshalOpt(&obj, test); err == nil {
+		t.Error("expected an error for incorrect value")
+	}
+
+	// The value is reset if the option isn't provided.
+	test = []string{"./app"}
+	if err := unmarshalOpt(&obj, test); err != nil {
+		t.Error(err)
+	}
+
+	if obj.Retries.IsSet() || obj.Debug.IsSet() || obj.IP != nil {
+		t.Errorf("expected unset values but %v", obj)
+	}
+
+	// Unsupported value type.
+	wrong := struct {
+		Ch Optional[chan int]
+	}{}
+
+	if err := Check(&wrong); err == nil {
+		t.Error("expected an error for unsupported type")
+	}
+}
+
+// TestOptionalBoolFlag tests the flag of Optional[bool] field,
+// which doesn't need a value like the flag of bool field.
+func TestOptionalBoolFlag(t *testing.T) {
+	type data struct {
+		Debug Optional[bool] `opt:"b"`
+		Cmd   string         `opt:"1"`
+		Rest  []string       `opt:"..."`
+		Raw   []string       `opt:"--"`
+	}
+
+	// The letters after the boolean flag are the unknown flags.
+	obj := data{}
+	test := []string{"./app", "-bx", "run"}
+	if err := Unmarshal(&obj, WithArgs(test)); err != nil {
+		t.Fatal(err)
+	}
+
+	if !obj.Debug.OrElse(false) || obj.Cmd != "run" ||
+		strings.Join(obj.Rest, " ") != "-x" {
+		t.Errorf("incorrect result %v", obj)
+	}
+
+	// In the stop mode, the boolean flag doesn't take the command.
+	obj = data{}
+	test = []string{"./app", "-b", "exec", "ls"}
+	err := Unmarshal(&obj, WithArgs(test), WithStopAtPositional())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !obj.Debug.OrElse(false) || obj.Cmd != "exec" ||
+		strings.Join(obj.Raw, " ") != "exec ls" {
+		t.Errorf("incorrect result %v", obj)
+	}
+}
@@ -0,0 +1,163 @@
+package opt
+
+import (
+	"io"
+	"os"
+)
+
+// Option customizes the parsing of the command line,
+// for example: WithArgs, WithVersion, WithOutput, etc.
+type Option func(*config)
+
+// The config is a set of the parsing settings.
+type config struct {
+	args       []string        // command line arguments, os.Args by default
+	version    *string         // version information, nil if not set
+	output     io.Writer       // writer for help and version information
+	errOutput  io.Writer       // writer for errors
+	exit       func(int)       // function to exit the program
+	respFile   rune            // prefix of the response files, 0 if disabled
+	builtin    fieldCastList   // built-in options, like --help
+	permissive bool            // accept every flag, see argMap.parseWith
+	known      bool            // keep unknown flags, see argMap.parseWith
+	rest       []string        // arguments which aren't consumed in known mode
+	stop       bool            // stop parsing flags at the first positional argument
+	strict     bool            // repeated flags of the non-list fields cause an error
+	bools      map[string]bool // flags of the boolean fields, see argMap.parseWith
+	noShortEq  bool            // = after the short flag is a part of its value
+	literals   bool            // parse the integers in the Go literal syntax
+
+	// Values of the built-in options.
+	help        bool
+	showVersion bool
+}
+
+// The newConfig returns config with default values
+// and applies the options to it.
+func newConfig(opts ...Option) *config {
+	cfg := &config{
+		args:      os.Args,
+		output:    os.Stdout,
+		errOutput: os.Stderr,
+		exit:      os.Exit,
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+// WithArgs sets the command line arguments for parsing instead of
+// os.Args. The first item is the path to the application.
+func WithArgs(args []string) Option {
+	return func(cfg *config) {
+		cfg.args = args
+	}
+}
+
+// WithVersion enables the built-in --version flag for ParseOrExit.
+// If the version is empty, it's taken from the build information
+// of the main module (see runtime/debug.ReadBuildInfo).
+func WithVersion(version string) Option {
+	return func(cfg *config) {
+		cfg.version = &version
+	}
+}
+
+// WithOutput sets the writer for help and version
+// information of ParseOrExit, os.Stdout by default.
+func WithOutput(w io.Writer) Option {
+	return func(cfg *config) {
+		cfg.output = w
+	}
+}
+
+// WithErrOutput sets the writer for parsing errors
+// of ParseOrExit, os.Stderr by default.
+func WithErrOutput(w io.Writer) Option {
+	return func(cfg *config) {
+		cfg.errOutput = w
+	}
+}
+
+// WithExit sets the function that is called by ParseOrExit to exit
+// the program, os.Exit by default. It can be used in tests to avoid
+// exiting.
+func WithExit(fn func(code int)) Option {
+	return func(cfg *config) {
+		cfg.exit = fn
+	}
+}
+
+// WithResponseFiles enables the response files: each argument that starts
+// with the prefix, like @path (if the prefix is '@'), is replaced by the
+// arguments from the file at the path. The content of the file is split
+// into arguments by the shell-like rules: single and double quotes,
+// backslash escapes and # comments, without any expansions.
+//
+// The response files can include other response files, the relative path
+// is resolved from the directory of the including file. The recursive
+// inclusion causes an error. The arguments after -- aren't expanded.
+func WithResponseFiles(prefix rune) Option {
+	return func(cfg *config) {
+		cfg.respFile = prefix
+	}
+}
+
+// WithStopAtPositional stops the parsing of the flags at the first
+// positional argument, all next arguments are positional, even if they
+// look like flags or are the -- separator. It's useful for the commands like: app exec [flags]
+// cmd --its-own-flags. The arguments after the stop point are set
+// into the `opt:"--"` field as is.
+//
+// In this mode, the flag of the boolean field doesn't take the next
+// argument as its value (use -v=false or --verbose=false), so the
+// app -v exec ls stops at exec.
+func WithStopAtPositional() Option {
+	return func(cfg *config) {
+		cfg.stop = true
+	}
+}
+
+// WithPosixlyCorrect enables the stop at the first positional argument
+// (see WithStopAtPositional) if the POSIXLY_CORRECT environment variable
+// is set, like the GNU getopt does.
+func WithPosixlyCorrect() Option {
+	return func(cfg *config) {
+		if _, ok := os.LookupEnv("POSIXLY_CORRECT"); ok {
+			cfg.stop = true
+		}
+	}
+}
+
+// WithStrict enables the strict mode: the flag of the field which isn't
+// a list (slice, array or map), given more than once, causes an error like:
+// flag --port given 2 times (argv 2, 7). By default, the last value is taken.
+// The strict tag enables this mode for the specific field.
+func WithStrict() Option {
+	return func(cfg *config) {
+		cfg.strict = true
+	}
+}
+
+// WithoutShortEquals disables the -p=value form of the short flags, so the
+// = sign after the short flag is a part of its value: -p=80 gives "=80".
+// It's useful for the tools where = is a legitimate first character of the
+// value, like the -e=x=1 expressions. By default, -p=80 is the same as -p80.
+func WithoutShortEquals() Option {
+	return func(cfg *config) {
+		cfg.noShortEq = true
+	}
+}
+
+// WithNumberLiterals enables the Go literal syntax for the integer values
+// of all fields: the base prefixes 0x, 0o (or 0), 0b and underscores, like
+// --mask 0xff, --mode 0755 or --count 1_000_000. The base tag of the field
+// overrides it.
+func WithNumberLiterals() Option {
+	return func(cfg *config) {
+		cfg.literals = true
+	}
+}
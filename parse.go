@@ -0,0 +1,82 @@
+package opt
+
+import (
+	"errors"
+	"reflect"
+)
+
+// The validateType checks whether the rt type is a structure or a pointer
+// to a structure which can be parsed, and returns the problem of its
+// definition. The structure is parsed only once, see getStructInfo.
+func validateType(rt reflect.Type) error {
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+
+	switch {
+	case rt.Kind() != reflect.Struct:
+		return errors.New("T should be a struct or a pointer to a struct")
+	case rt.NumField() == 0:
+		return errors.New("T should be a non-empty struct")
+	}
+
+	return getStructInfo(rt).err
+}
+
+// Parse allocates the value of the T type, parses the command-line
+// options into it, like Unmarshal, and returns it. The T should be
+// a structure or a pointer to a structure.
+//
+// The opts customize the parsing like for Unmarshal, for example,
+// WithArgs sets the command line arguments instead of os.Args:
+//
+//	type Args struct {
+//		Host string `opt:"H" alt:"host" def:"localhost"`
+//		Port int    `opt:"p" alt:"port" def:"8080"`
+//	}
+//
+//	args, err := opt.Parse[Args]()
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	// Without arguments, the path to the application only.
+//	args, err = opt.Parse[Args](opt.WithArgs([]string{"./app"}))
+//
+// Unlike Unmarshal, the Parse doesn't panic if the T has problems in the
+// field definitions, it returns them as error. The structure is parsed
+// only once for each type.
+func Parse[T any](opts ...Option) (T, error) {
+	var result T
+
+	rt := reflect.TypeOf((*T)(nil)).Elem()
+	if err := validateType(rt); err != nil {
+		return result, err
+	}
+
+	// The pointer to the structure is allocated.
+	obj := interface{}(&result)
+	if rt.Kind() == reflect.Ptr {
+		rv := reflect.New(rt.Elem())
+		result, obj = rv.Interface().(T), rv.Interface()
+	}
+
+	cfg := newConfig(opts...)
+	if errs := decodeOpt(obj, cfg.args, cfg); errs != nil {
+		// Returns only the first error.
+		return result, errs[0]
+	}
+
+	return result, nil
+}
+
+// MustParse is like Parse but panics if the command line
+// or the T type has an error.
+func MustParse[T any](opts ...Option) T {
+	result, err := Parse[T](opts...)
+	if err != nil {
+		panic(err)
+	}
+
+	return result
+}
@@ -0,0 +1,91 @@
+package opt
+
+import (
+	"os"
+	"testing"
+)
+
+// TestParseGeneric tests Parse function.
+func TestParseGeneric(t *testing.T) {
+	type data struct {
+		Host string `opt:"H" alt:"host" def:"localhost"`
+		Port int    `opt:"p" alt:"port" def:"8080"`
+		Pos  []int  `opt:"[]"`
+	}
+
+	args, err := Parse[data](WithArgs([]string{"./app", "-H", "0.0.0.0",
+		"--port", "80", "1", "2"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if args.Host != "0.0.0.0" || args.Port != 80 || len(args.Pos) != 2 {
+		t.Errorf("incorrect result %v", args)
+	}
+
+	// The os.Args is used without the WithArgs option.
+	defer func(args []string) { os.Args = args }(os.Args)
+	os.Args = []string{"./app", "-p", "90"}
+
+	ptr, err := Parse[*data]()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ptr == nil || ptr.Host != "localhost" || ptr.Port != 90 {
+		t.Errorf("incorrect result %v", ptr)
+	}
+
+	// The empty command line isn't replaced by the os.Args.
+	args, err = Parse[data](WithArgs([]string{"./app"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if args.Host != "localhost" || args.Port != 8080 || len(args.Pos) != 0 {
+		t.Errorf("incorrect result %v", args)
+	}
+
+	_, err = Parse[data](WithArgs([]string{"./app", "--user", "John"}))
+	if err == nil {
+		t.Error("expected an error for unknown flag")
+	}
+
+	// The problems of the type are returned as error.
+	if _, err := Parse[int](); err == nil {
+		t.Error("expected an error for non-struct type")
+	}
+
+	if _, err := Parse[struct{}](); err == nil {
+		t.Error("expected an error for empty struct")
+	}
+
+	type wrong struct {
+		Doc int `opt:"?"`
+	}
+
+	for i := 0; i < 2; i++ { // the second time from the cache
+		if _, err := Parse[wrong](); err == nil {
+			t.Error("expected an error for incorrect field")
+		}
+	}
+}
+
+// TestMustParse tests MustParse function.
+func TestMustParse(t *testing.T) {
+	type data struct {
+		Port int `opt:"p" alt:"port"`
+	}
+
+	args := MustParse[data](WithArgs([]string{"./app", "-p", "80"}))
+	if args.Port != 80 {
+		t.Errorf("expected 80 but %d", args.Port)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("expected panic for incorrect value")
+		}
+	}()
+	MustParse[data](WithArgs([]string{"./app", "-p", "http"}))
+}
@@ -0,0 +1,130 @@
+package opt
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// The expandResponseFiles replaces each argument that starts with the
+// prefix, like @path, by the content of the file at the path. The content
+// is split into arguments by the shell-like rules (see splitWords).
+//
+// The response files can include other response files, the relative path
+// is resolved from the directory of the including file. The recursive
+// inclusion causes an error. The first argument (path to the application)
+// and the arguments after the -- separator aren't expanded.
+func expandResponseFiles(args []string, prefix rune) ([]string, error) {
+	if len(args) == 0 {
+		return args, nil
+	}
+
+	result := make([]string, 0, len(args))
+	result = append(result, args[0])
+
+	rest, err := expandResponseArgs(args[1:], string(prefix), "", nil)
+	if err != nil {
+		return args, err
+	}
+
+	return append(result, rest...), nil
+}
+
+// The expandResponseArgs expands the response files in the args.
+// The dir is a directory for relative paths, the stack is a list of
+// the absolute paths of the response files being expanded.
+func expandResponseArgs(
+	args []string,
+	prefix,
+	dir string,
+	stack []string,
+) ([]string, error) {
+	result := make([]string, 0, len(args))
+	for i, arg := range args {
+		if arg == "--" {
+			// Arguments after the separator are kept as is.
+			return append(result, args[i:]...), nil
+		}
+
+		path := strings.TrimPrefix(arg, prefix)
+		if path == arg || path == "" {
+			result = append(result, arg)
+			continue
+		}
+
+		items, err := readResponseFile(path, prefix, dir, stack)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, items...)
+	}
+
+	return result, nil
+}
+
+// The readResponseFile reads the response file at path
+// and returns its expanded arguments.
+func readResponseFile(
+	path,
+	prefix,
+	dir string,
+	stack []string,
+) ([]string, error) {
+	if !filepath.IsAbs(path) && dir != "" {
+		path = filepath.Join(dir, path)
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range stack {
+		if p == abs {
+			return nil, fmt.Errorf("%s: recursive response file", path)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	words, err := splitWords(string(data))
+	if err != nil {
+		if e, ok := err.(*SplitError); ok {
+			return nil, fmt.Errorf("%s:%d:%d: %s",
+				path, e.Line, e.Column, e.Msg)
+		}
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	// The nested response files are expanded one by one
+	// to report the line where they are included.
+	stack = append(stack[:len(stack):len(stack)], abs)
+	result := make([]string, 0, len(words))
+	for i, w := range words {
+		if w.value == "--" {
+			for _, w := range words[i:] {
+				result = append(result, w.value)
+			}
+			break
+		}
+
+		items, err := expandResponseArgs(
+			[]string{w.value},
+			prefix,
+			filepath.Dir(path),
+			stack,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, w.line, err)
+		}
+
+		result = append(result, items...)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,103 @@
+package opt
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestExpandResponseFiles tests expandResponseFiles function.
+func TestExpandResponseFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.rsp":   "-d # debug mode\n--name 'John Doe'\n@sub/a.rsp",
+		"sub/a.rsp":  "-p 80 @b.rsp",
+		"sub/b.rsp":  "--host=localhost",
+		"loop.rsp":   "-d\n@loop2.rsp",
+		"loop2.rsp":  "-v @loop.rsp",
+		"quote.rsp":  "-d\n--name 'John",
+		"broken.rsp": "-d\n@none.rsp",
+	}
+
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	path := func(name string) string { return filepath.Join(dir, name) }
+	args := []string{"@app", "-v", "@" + path("main.rsp"), "--", "@x"}
+	exp := []string{"@app", "-v", "-d", "--name", "John Doe", "-p", "80",
+		"--host=localhost", "--", "@x"}
+
+	r, err := expandResponseFiles(args, '@')
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(r, exp) {
+		t.Errorf("expected %q but %q", exp, r)
+	}
+
+	// Errors.
+	tests := []struct {
+		name string
+		err  string
+	}{
+		{"loop.rsp", "loop.rsp:2: " + path("loop2.rsp") + ":1: " +
+			path("loop.rsp") + ": recursive response file"},
+		{"quote.rsp", "quote.rsp:2:8: unterminated single quote"},
+		{"broken.rsp", "broken.rsp:2: open " + path("none.rsp")},
+		{"none.rsp", "none.rsp"},
+	}
+
+	for i, test := range tests {
+		args := []string{"./app", "@" + path(test.name)}
+		_, err := expandResponseFiles(args, '@')
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%d test, expected %q but %v", i, test.err, err)
+		}
+	}
+}
+
+// TestUnmarshalResponseFiles tests Unmarshal with response files.
+func TestUnmarshalResponseFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "args.txt")
+	err := os.WriteFile(path, []byte("--host example.com\n-p 80"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type data struct {
+		Host string `opt:"host"`
+		Port int    `opt:"p"`
+	}
+
+	args := data{}
+	test := []string{"./app", "+" + path, "-p", "8080"}
+	if err := Unmarshal(&args, WithArgs(test),
+		WithResponseFiles('+')); err != nil {
+		t.Error(err)
+	}
+
+	if e := (data{"example.com", 8080}); args != e {
+		t.Errorf("expected %v but %v", e, args)
+	}
+
+	// Disabled by default.
+	args = data{}
+	if err := Unmarshal(&args, WithArgs(test)); err != nil {
+		t.Error(err)
+	}
+
+	if e := (data{"", 8080}); args != e {
+		t.Errorf("expected %v but %v", e, args)
+	}
+}
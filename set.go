@@ -0,0 +1,283 @@
+package opt
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Set is a set of the command-line options built programmatically,
+// without the structure and tags, for example, when the options are
+// known at runtime only. The options are parsed by the same rules as
+// the fields of the structure.
+//
+// The methods that add options can be chained, the first problem of the
+// option definition is returned by the Parse method:
+//
+//	set := opt.NewSet().
+//		String("host", 'H', "localhost", "host of the server").
+//		Int("port", 'p', 8080, "port of the server").
+//		Bool("debug", 'd', false, "debug mode")
+//
+//	if err := set.Parse(); err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	host, port := set.GetString("host"), set.GetInt("port")
+type Set struct {
+	fields fieldCastList         // options in the order of addition
+	names  map[string]*fieldCast // options by the long and short names
+	args   []string              // positional arguments after parsing
+	err    error                 // first problem of the option definition
+}
+
+// NewSet returns a new empty set of the options.
+func NewSet() *Set {
+	return &Set{names: make(map[string]*fieldCast)}
+}
+
+// Var adds the option which value is stored into the value, it should be a
+// pointer to the variable of any type supported for the structure fields.
+// The name is a long flag name, the short is a short flag name (0 if the
+// option doesn't have it), the def is a default value as it's written in
+// the def tag, the help is a brief help about the option.
+//
+// If the list or map option gets several values in one argument,
+// they are divided by comma.
+func (s *Set) Var(
+	name string,
+	short rune,
+	value interface{},
+	def,
+	help string,
+) *Set {
+	if s.err != nil {
+		return s
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		s.err = fmt.Errorf("%s option value should be a non-nil pointer",
+			name)
+		return s
+	}
+
+	item := rv.Elem()
+	if !isSupported(item.Type()) {
+		s.err = fmt.Errorf("%s option has invalid type", name)
+		return s
+	}
+
+	alt := ""
+	if short != 0 {
+		alt = string(short)
+	}
+
+	sep := ""
+	switch item.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		sep = ","
+	}
+
+	tg, err := getTagGroup(name, name, alt, def, sep, help)
+	if err == nil {
+		err = setTagOptions(&tg, "")
+		tg.isBool = isBool(item.Type())
+	}
+
+	switch {
+	case err != nil:
+		s.err = fmt.Errorf("%s option: %v", name, err)
+		return s
+	case name == "" || isSpecialFlag(tg.shortFlag):
+		s.err = fmt.Errorf("invalid option name %q", name)
+		return s
+	}
+
+	fc := &fieldCast{fieldName: name, tagGroup: &tg, item: &item}
+	for _, flag := range []string{tg.shortFlag, tg.longFlag} {
+		if flag == "" {
+			continue
+		} else if _, ok := s.names[flag]; ok {
+			s.err = fmt.Errorf("%s flag is declared more than once", flag)
+			return s
+		}
+	}
+
+	for _, flag := range []string{tg.shortFlag, tg.longFlag} {
+		if flag != "" {
+			s.names[flag] = fc
+		}
+	}
+
+	s.fields = append(s.fields, fc)
+	return s
+}
+
+// String adds the option of the string type.
+func (s *Set) String(name string, short rune, def, help string) *Set {
+	return s.Var(name, short, new(string), def, help)
+}
+
+// Int adds the option of the int type.
+func (s *Set) Int(name string, short rune, def int, help string) *Set {
+	value := ""
+	if def != 0 {
+		value = strconv.Itoa(def)
+	}
+
+	return s.Var(name, short, new(int), value, help)
+}
+
+// Float64 adds the option of the float64 type.
+func (s *Set) Float64(name string, short rune, def float64,
+	help string) *Set {
+	value := ""
+	if def != 0 {
+		value = strconv.FormatFloat(def, 'g', -1, 64)
+	}
+
+	return s.Var(name, short, new(float64), value, help)
+}
+
+// Bool adds the option of the bool type.
+func (s *Set) Bool(name string, short rune, def bool, help string) *Set {
+	value := ""
+	if def {
+		value = "true"
+	}
+
+	return s.Var(name, short, new(bool), value, help)
+}
+
+// Strings adds the option of the []string type,
+// the values can be separated by comma.
+func (s *Set) Strings(name string, short rune, def []string,
+	help string) *Set {
+	return s.Var(name, short, new([]string), strings.Join(def, ","), help)
+}
+
+// Parse parses the command-line options into the set, like Unmarshal.
+// The opts customize the parsing, for example, WithArgs sets the command
+// line arguments instead of os.Args.
+//
+// Returns the first problem of the option definition,
+// or the first error of the command line.
+func (s *Set) Parse(opts ...Option) error {
+	if s.err != nil {
+		return s.err
+	} else if len(s.fields) == 0 {
+		return errors.New("set should have at least one option")
+	}
+
+	cfg := newConfig(opts...)
+	args := cfg.args
+	if cfg.respFile != 0 {
+		tmp, err := expandResponseFiles(args, cfg.respFile)
+		if err != nil {
+			return err
+		}
+		args = tmp
+	}
+
+	am := argMap{}
+	cfg.bools = s.fields.boolFlags()
+	if err := am.parseWith(args, s.fields.flags(), cfg); err != nil {
+		return err
+	}
+
+	// The values of the list and map types are accumulated,
+	// so the values of the previous parsing are reset.
+	var errs []error
+	for _, fc := range s.fields {
+		fc.item.Set(reflect.Zero(fc.item.Type()))
+		if err := fc.fill(s.fields, am, cfg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	s.args = am.posValues()
+	if len(errs) != 0 {
+		// Returns only the first error.
+		return errs[0]
+	}
+
+	return nil
+}
+
+// Args returns the positional arguments after parsing,
+// without the path to the application.
+func (s *Set) Args() []string {
+	return s.args
+}
+
+// Help returns the help information about the options of the set.
+func (s *Set) Help() string {
+	return getHelp(s.fields, argMap{})
+}
+
+// Get returns the value of the option by its long or short name,
+// or nil if there is no such option.
+func (s *Set) Get(name string) interface{} {
+	// The short names are case sensitive, the long names aren't.
+	if fc, ok := s.names[name]; ok {
+		return fc.item.Interface()
+	} else if fc, ok := s.names[strings.ToLower(name)]; ok {
+		return fc.item.Interface()
+	}
+
+	return nil
+}
+
+// GetString returns the value of the option of the string type,
+// or empty string if there is no such option.
+func (s *Set) GetString(name string) string {
+	v, _ := s.Get(name).(string)
+	return v
+}
+
+// GetInt returns the value of the option of the int type,
+// or 0 if there is no such option.
+func (s *Set) GetInt(name string) int {
+	v, _ := s.Get(name).(int)
+	return v
+}
+
+// GetFloat64 returns the value of the option of the float64 type,
+// or 0 if there is no such option.
+func (s *Set) GetFloat64(name string) float64 {
+	v, _ := s.Get(name).(float64)
+	return v
+}
+
+// GetBool returns the value of the option of the bool type,
+// or false if there is no such option.
+func (s *Set) GetBool(name string) bool {
+	v, _ := s.Get(name).(bool)
+	return v
+}
+
+// GetStrings returns the value of the option of the []string type,
+// or nil if there is no such option.
+func (s *Set) GetStrings(name string) []string {
+	v, _ := s.Get(name).([]string)
+	return v
+}
+
+// Values returns the values of all options by their names: the long
+// name, or the short name if the option doesn't have the long one.
+func (s *Set) Values() map[string]interface{} {
+	result := make(map[string]interface{}, len(s.fields))
+	for _, fc := range s.fields {
+		name := fc.tagGroup.longFlag
+		if name == "" {
+			name = fc.tagGroup.shortFlag
+		}
+
+		result[name] = fc.item.Interface()
+	}
+
+	return result
+}
@@ -0,0 +1,109 @@
+package opt
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// TestSet tests the Set builder.
+func TestSet(t *testing.T) {
+	var site url.URL
+	set := NewSet().
+		String("host", 'H', "localhost", "host of the server").
+		Int("port", 'p', 8080, "port of the server").
+		Float64("ratio", 0, 0.5, "").
+		Bool("debug", 'd', false, "debug mode").
+		Strings("user", 'U', nil, "").
+		Var("site", 0, &site, "", "")
+
+	args := []string{"./app", "-d", "--port", "80", "-U", "John,Bob",
+		"--site", "example.com", "5", "10"}
+	if err := set.Parse(WithArgs(args)); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := set.GetString("host"); v != "localhost" {
+		t.Errorf("expected localhost but %s", v)
+	}
+
+	if v := set.GetInt("p"); v != 80 {
+		t.Errorf("expected 80 but %d", v)
+	}
+
+	if v := set.GetFloat64("ratio"); v != 0.5 {
+		t.Errorf("expected 0.5 but %f", v)
+	}
+
+	if !set.GetBool("Debug") {
+		t.Error("expected true")
+	}
+
+	if v := set.GetStrings("U"); !reflect.DeepEqual(v, []string{"John",
+		"Bob"}) {
+		t.Errorf("expected [John Bob] but %v", v)
+	}
+
+	if site.Host != "" || site.Path != "example.com" {
+		t.Errorf("expected example.com but %v", site)
+	}
+
+	if v := set.Args(); !reflect.DeepEqual(v, []string{"5", "10"}) {
+		t.Errorf("expected [5 10] but %v", v)
+	}
+
+	values := set.Values()
+	if len(values) != 6 || values["port"] != 80 || values["debug"] != true {
+		t.Errorf("incorrect values %v", values)
+	}
+
+	if set.Get("unknown") != nil || set.GetInt("host") != 0 {
+		t.Error("expected zero values for unknown options")
+	}
+
+	// The values of the previous parsing are reset.
+	if err := set.Parse(WithArgs([]string{"./app", "-U", "Roy"})); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := set.GetStrings("user"); !reflect.DeepEqual(v, []string{"Roy"}) {
+		t.Errorf("expected [Roy] but %v", v)
+	}
+
+	if set.GetBool("debug") || set.GetInt("port") != 8080 {
+		t.Errorf("expected default values but %v", set.Values())
+	}
+
+	if err := set.Parse(WithArgs([]string{"./app", "-p", "http"})); err == nil {
+		t.Error("expected an error for incorrect value")
+	}
+
+	exp := "Options:\n" +
+		"    -H, --host  host of the server (default: localhost);\n" +
+		"    -p, --port  port of the server (default: 8080);\n" +
+		"    -d, --debug debug mode."
+	if v := set.Help(); v != exp {
+		t.Errorf("expected %q but %q", exp, v)
+	}
+}
+
+// TestSetErrors tests the Set builder with incorrect options.
+func TestSetErrors(t *testing.T) {
+	tests := []*Set{
+		NewSet(),
+		NewSet().String("host", 'h', "", "").Int("port", 'h', 0, ""),
+		NewSet().String("host", 0, "", "").Int("host", 0, 0, ""),
+		NewSet().String("***", 0, "", ""),
+		NewSet().String("", 'h', "", ""),
+		NewSet().String("?", 0, "", ""),
+		NewSet().Var("ch", 0, new(chan int), "", ""),
+		NewSet().Var("value", 0, 5, "", ""),
+		NewSet().Var("value", 0, (*int)(nil), "", ""),
+	}
+
+	for i, set := range tests {
+		if err := set.Parse(WithArgs([]string{"./app"})); err == nil {
+			t.Errorf("%d test, expected an error", i)
+		}
+	}
+}
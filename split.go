@@ -0,0 +1,161 @@
+package opt
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// The splitValues divides the values of the list field into items by the
+// separator from the sep tag, according to the split tag: the items can be
+// quoted (csv) or can contain escaped characters (escape), the spaces around
+// the items can be trimmed (trim) and the empty items can be rejected
+// (noempty). Without separator, each value is one item.
+func (tg *tagGroup) splitValues(value []string) ([]string, error) {
+	result := make([]string, 0, len(value))
+	for _, v := range value {
+		var items []string
+		var err error
+
+		switch {
+		case tg.sepList == "":
+			items = []string{v}
+		case tg.splitMode == splitCSV:
+			items, err = splitCSVItems(v, tg.sepList, tg.trimItems)
+		case tg.splitMode == splitEscape:
+			items = splitEscapedItems(v, tg.sepList, tg.trimItems)
+		case tg.trimItems:
+			items = strings.Split(v, tg.sepList)
+			for i, item := range items {
+				items[i] = strings.TrimSpace(item)
+			}
+		default:
+			items = strings.Split(v, tg.sepList)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is incorrect list: %v", v, err)
+		}
+
+		for _, item := range items {
+			if tg.noEmpty && item == "" {
+				return nil, fmt.Errorf("'%s' is incorrect list: "+
+					"empty item", v)
+			}
+
+			result = append(result, item)
+		}
+	}
+
+	return result, nil
+}
+
+// The splitCSVItems splits the str into items by the sep like RFC 4180:
+// the item enclosed in double quotes can contain the separator, the
+// double quote inside it is escaped by another double quote, like:
+// "a, b","say ""hi""" gives [a, b] and [say "hi"]. The spaces around
+// the quoted item are ignored. If the trim is true, the spaces around
+// the unquoted item are removed.
+func splitCSVItems(str, sep string, trim bool) ([]string, error) {
+	var result []string
+
+	for {
+		rest := strings.TrimLeftFunc(str, unicode.IsSpace)
+		if !strings.HasPrefix(rest, `"`) {
+			// Unquoted item, up to the separator.
+			item, tail, found := strings.Cut(str, sep)
+			if trim {
+				item = strings.TrimSpace(item)
+			}
+
+			result = append(result, item)
+			if !found {
+				return result, nil
+			}
+
+			str = tail
+			continue
+		}
+
+		// Quoted item, up to the closing double quote.
+		var b strings.Builder
+		rest = rest[1:]
+		for {
+			i := strings.IndexByte(rest, '"')
+			if i < 0 {
+				return nil, errors.New("unterminated quote")
+			}
+
+			b.WriteString(rest[:i])
+			rest = rest[i+1:]
+			if !strings.HasPrefix(rest, `"`) {
+				break
+			}
+
+			b.WriteByte('"')
+			rest = rest[1:]
+		}
+
+		result = append(result, b.String())
+		rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+		switch {
+		case rest == "":
+			return result, nil
+		case !strings.HasPrefix(rest, sep):
+			return nil, errors.New("unexpected character after quote")
+		}
+
+		str = rest[len(sep):]
+	}
+}
+
+// The splitEscapedItems splits the str into items by the sep, the
+// backslash escapes the next character, so the item can contain the
+// separator: a\,b,c gives [a,b] and [c]. The trailing backslash is kept.
+// If the trim is true, the spaces around the item are removed, except
+// the escaped ones.
+func splitEscapedItems(str, sep string, trim bool) []string {
+	var result []string
+	var b strings.Builder
+
+	// The keep is the length of the item without the trailing spaces.
+	keep := 0
+	add := func() {
+		item := b.String()
+		if trim {
+			item = item[:keep]
+		}
+
+		result = append(result, item)
+		b.Reset()
+		keep = 0
+	}
+
+	for i := 0; i < len(str); {
+		switch {
+		case str[i] == '\\' && i+1 < len(str):
+			// The escaped character is always kept, even a space.
+			_, size := utf8.DecodeRuneInString(str[i+1:])
+			b.WriteString(str[i+1 : i+1+size])
+			keep = b.Len()
+			i += 1 + size
+		case strings.HasPrefix(str[i:], sep):
+			add()
+			i += len(sep)
+		default:
+			r, size := utf8.DecodeRuneInString(str[i:])
+			if !(trim && unicode.IsSpace(r) && b.Len() == 0) {
+				b.WriteString(str[i : i+size])
+				if !unicode.IsSpace(r) {
+					keep = b.Len()
+				}
+			}
+			i += size
+		}
+	}
+
+	add()
+	return result
+}
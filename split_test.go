@@ -0,0 +1,139 @@
+package opt
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestSplitValues tests splitValues method.
+func TestSplitValues(t *testing.T) {
+	tests := []struct {
+		tag      reflect.StructTag
+		value    []string
+		expected []string
+	}{
+		{`sep:","`, []string{"a, b", "c"}, []string{"a", " b", "c"}},
+		{`sep:"," split:"trim"`, []string{" a , b "}, []string{"a", "b"}},
+		{`split:"trim"`, []string{" a , b "}, []string{" a , b "}},
+		{
+			`sep:"," split:"csv"`,
+			[]string{`"a, b", c,"say ""hi""",`},
+			[]string{"a, b", " c", `say "hi"`, ""},
+		},
+		{
+			`sep:"," split:"csv,trim"`,
+			[]string{` " a, b " , c `},
+			[]string{" a, b ", "c"},
+		},
+		{
+			`sep:"::" split:"csv"`,
+			[]string{`"a::b"::c`},
+			[]string{"a::b", "c"},
+		},
+		{
+			`sep:"," split:"escape"`,
+			[]string{`a\,b,c\\,d\`},
+			[]string{"a,b", `c\`, `d\`},
+		},
+		{
+			`sep:"," split:"escape,trim"`,
+			[]string{` a\ , b `},
+			[]string{"a ", "b"},
+		},
+	}
+
+	for i, test := range tests {
+		tg := tagGroup{sepList: test.tag.Get(tagNameSepList)}
+		if err := setTagOptions(&tg, test.tag); err != nil {
+			t.Errorf("%d test, %v", i, err)
+			continue
+		}
+
+		result, err := tg.splitValues(test.value)
+		if err != nil {
+			t.Errorf("%d test, %v", i, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("%d test, expected %q but %q", i, test.expected, result)
+		}
+	}
+}
+
+// TestSplitValuesErrors tests splitValues method with errors.
+func TestSplitValuesErrors(t *testing.T) {
+	tests := []struct {
+		tag   reflect.StructTag
+		value string
+		err   string
+	}{
+		{`sep:"," split:"csv"`, `"a,b`,
+			`'"a,b' is incorrect list: unterminated quote`},
+		{`sep:"," split:"csv"`, `"a"b,c`,
+			`'"a"b,c' is incorrect list: unexpected character after quote`},
+		{`sep:"," split:"noempty"`, "a,,b",
+			`'a,,b' is incorrect list: empty item`},
+		{`sep:"," split:"trim,noempty"`, "a, ",
+			`'a, ' is incorrect list: empty item`},
+	}
+
+	for i, test := range tests {
+		tg := tagGroup{sepList: test.tag.Get(tagNameSepList)}
+		if err := setTagOptions(&tg, test.tag); err != nil {
+			t.Errorf("%d test, %v", i, err)
+			continue
+		}
+
+		_, err := tg.splitValues([]string{test.value})
+		if err == nil || err.Error() != test.err {
+			t.Errorf("%d test, expected %q but %v", i, test.err, err)
+		}
+	}
+
+	// Incorrect tags.
+	for _, tag := range []reflect.StructTag{
+		`split:"csv,escape"`,
+		`split:"any"`,
+	} {
+		if err := setTagOptions(&tagGroup{}, tag); err == nil {
+			t.Errorf("expected an error for %s", tag)
+		}
+	}
+}
+
+// TestSplitTag tests split tag for the list fields.
+func TestSplitTag(t *testing.T) {
+	args := struct {
+		Header map[string]string `opt:"H" kvsep:":" sep:"," split:"csv,trim"`
+		Files  []string          `opt:"f" sep:"," split:"escape"`
+		Pair   [2]int            `opt:"p" sep:"," split:"trim" def:"1, 2"`
+	}{}
+
+	errs := unmarshalOpt(&args, []string{"./app",
+		"-H", `"Accept: a, b", Host: x`, "-f", `a\,b.txt,c.txt`})
+	if len(errs) != 0 {
+		t.Fatal(errs)
+	}
+
+	header := map[string]string{"Accept": " a, b", "Host": " x"}
+	if !reflect.DeepEqual(args.Header, header) {
+		t.Errorf("expected %q but %q", header, args.Header)
+	}
+
+	files := []string{"a,b.txt", "c.txt"}
+	if !reflect.DeepEqual(args.Files, files) {
+		t.Errorf("expected %q but %q", files, args.Files)
+	}
+
+	if args.Pair != [2]int{1, 2} {
+		t.Errorf("expected [1 2] but %v", args.Pair)
+	}
+
+	wrong := struct {
+		Name string `split:"trim"`
+	}{}
+	if err := Check(&wrong); err == nil {
+		t.Error("expected an error for split tag of the string field")
+	}
+}
@@ -1,6 +1,9 @@
 package opt
 
-import "strings"
+import (
+	"strconv"
+	"strings"
+)
 
 // The split splits the string on elements using a colon as a separator.
 //
@@ -8,3 +11,14 @@ import "strings"
 func split(str string) []string {
 	return strings.Split(str, ":")
 }
+
+// The joinInts joins the numbers into the string separated by comma
+// and space, like: 2, 7.
+func joinInts(items []int) string {
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		result = append(result, strconv.Itoa(item))
+	}
+
+	return strings.Join(result, ", ")
+}
@@ -16,3 +16,21 @@ func TestToolsSplit(t *testing.T) {
 		t.Errorf("expected %v but %v", expected, v)
 	}
 }
+
+// TestToolsJoinInts tests the joinInts function.
+func TestToolsJoinInts(t *testing.T) {
+	tests := []struct {
+		items    []int
+		expected string
+	}{
+		{nil, ""},
+		{[]int{2}, "2"},
+		{[]int{2, 7}, "2, 7"},
+	}
+
+	for _, test := range tests {
+		if v := joinInts(test.items); v != test.expected {
+			t.Errorf("expected %q but %q", test.expected, v)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package opt
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	// The unitBytes and unitSI are the allowed values of the tagNameUnit
+	// tag: the size in bytes with SI or IEC suffix, like 512K, 10MiB or
+	// 1.5GB, and the count with SI suffix, like 10k or 2.5M.
+	unitBytes = "bytes"
+	unitSI    = "si"
+)
+
+// The unit is a suffix of the value and its multiplier.
+type unit struct {
+	suffix string
+	value  uint64
+}
+
+var (
+	// The byteUnits are the suffixes of the sizes in bytes, they are case
+	// insensitive. Like in GNU coreutils, the one-letter suffixes and the
+	// IEC suffixes are powers of 1024, the SI suffixes are powers of 1000.
+	byteUnits = []unit{
+		{"", 1}, {"b", 1},
+		{"k", 1 << 10}, {"kib", 1 << 10}, {"kb", 1e3},
+		{"m", 1 << 20}, {"mib", 1 << 20}, {"mb", 1e6},
+		{"g", 1 << 30}, {"gib", 1 << 30}, {"gb", 1e9},
+		{"t", 1 << 40}, {"tib", 1 << 40}, {"tb", 1e12},
+		{"p", 1 << 50}, {"pib", 1 << 50}, {"pb", 1e15},
+		{"e", 1 << 60}, {"eib", 1 << 60}, {"eb", 1e18},
+	}
+
+	// The siUnits are the SI suffixes of the counts, they are case
+	// sensitive, except k and K for the kilo.
+	siUnits = []unit{
+		{"", 1}, {"k", 1e3}, {"K", 1e3}, {"M", 1e6}, {"G", 1e9},
+		{"T", 1e12}, {"P", 1e15}, {"E", 1e18},
+	}
+
+	// The byteFormats and siFormats are the suffixes which are used to
+	// display the values, from the largest to the smallest.
+	byteFormats = []unit{
+		{"EiB", 1 << 60}, {"EB", 1e18}, {"PiB", 1 << 50}, {"PB", 1e15},
+		{"TiB", 1 << 40}, {"TB", 1e12}, {"GiB", 1 << 30}, {"GB", 1e9},
+		{"MiB", 1 << 20}, {"MB", 1e6}, {"KiB", 1 << 10}, {"KB", 1e3},
+	}
+	siFormats = []unit{
+		{"E", 1e18}, {"P", 1e15}, {"T", 1e12}, {"G", 1e9}, {"M", 1e6},
+		{"k", 1e3},
+	}
+
+	// The byteSizeType is the type of ByteSize.
+	byteSizeType = reflect.TypeOf(ByteSize(0))
+)
+
+// ByteSize is a size in bytes which is parsed from the human-readable
+// value with SI or IEC suffix: 512K, 10MiB, 1.5GB, etc. The suffixes
+// are case insensitive. Like in GNU coreutils, the one-letter suffixes
+// (K, M, G, T, P, E) and the IEC suffixes (KiB, MiB, ...) are powers of
+// 1024, the SI suffixes (KB, MB, ...) are powers of 1000. The value
+// without suffix or with the B suffix is in bytes.
+//
+// The same values are accepted by the integer fields
+// with the `unit:"bytes"` tag.
+type ByteSize uint64
+
+// String returns the size in the human-readable units, like 10MiB
+// or 1.5GB, the size which can't be shown exactly is shown in bytes.
+func (b ByteSize) String() string {
+	return formatUnits(uint64(b), unitBytes)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (b ByteSize) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	neg, r, err := parseUnits(string(text), unitBytes)
+	if err == nil && neg && r != 0 {
+		err = fmt.Errorf("'%s' has incorrect type, "+
+			"positive number expected", text)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	*b = ByteSize(r)
+	return nil
+}
+
+// The parseUnits parses the value with the suffix of the unit (see
+// unitBytes and unitSI), like 1.5K, and returns its absolute value
+// multiplied by the suffix. The neg is true for the negative value.
+func parseUnits(value, unitName string) (neg bool, r uint64, err error) {
+	str := strings.TrimSpace(value)
+	if str == "" {
+		return false, 0, nil
+	}
+
+	if str[0] == '-' || str[0] == '+' {
+		neg, str = str[0] == '-', str[1:]
+	}
+
+	// Divide the number and the suffix.
+	i := strings.IndexFunc(str, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(str)
+	}
+
+	num, suffix := str[:i], strings.TrimSpace(str[i:])
+	units := siUnits
+	if unitName == unitBytes {
+		units, suffix = byteUnits, strings.ToLower(suffix)
+	}
+
+	var m uint64
+	for _, u := range units {
+		if u.suffix == suffix {
+			m = u.value
+			break
+		}
+	}
+
+	if m == 0 || num == "" {
+		return false, 0, fmt.Errorf("'%s' is incorrect value", value)
+	}
+
+	// The fractional number is converted approximately,
+	// the integer number is checked for the overflow.
+	if strings.Contains(num, ".") {
+		f, err := strconv.ParseFloat(num, 64)
+		if err != nil {
+			return false, 0, fmt.Errorf("'%s' is incorrect value", value)
+		}
+
+		f = math.Round(f * float64(m))
+		if f >= math.MaxUint64 {
+			return false, 0, fmt.Errorf("%s overflows uint64", value)
+		}
+
+		return neg, uint64(f), nil
+	}
+
+	n, err := strconv.ParseUint(num, 10, 64)
+	if err != nil {
+		return false, 0, fmt.Errorf("'%s' is incorrect value", value)
+	}
+
+	hi, r := bits.Mul64(n, m)
+	if hi != 0 {
+		return false, 0, fmt.Errorf("%s overflows uint64", value)
+	}
+
+	return neg, r, nil
+}
+
+// The unitsToDec converts the value with the suffix of the unit,
+// like 1.5K, to the decimal number, like 1536.
+func unitsToDec(value, unitName string) (string, error) {
+	neg, r, err := parseUnits(value, unitName)
+	if err != nil {
+		return "", err
+	}
+
+	result := strconv.FormatUint(r, 10)
+	if neg && r != 0 {
+		result = "-" + result
+	}
+
+	return result, nil
+}
+
+// The formatUnits returns the value in the largest unit in which it can
+// be shown exactly with at most three decimal places, like 1.5GB for the
+// 1500000000 bytes, otherwise the number as is (in bytes for unitBytes).
+func formatUnits(value uint64, unitName string) string {
+	formats, suffix := siFormats, ""
+	if unitName == unitBytes {
+		formats, suffix = byteFormats, "B"
+	}
+
+	// The formats are sorted from the largest, so the first
+	// suitable unit is the best one.
+	var best unit
+	for _, u := range formats {
+		hi, lo := bits.Mul64(value, 1000)
+		if value < u.value || hi >= u.value {
+			continue
+		}
+
+		if _, rem := bits.Div64(hi, lo, u.value); rem == 0 {
+			best = u
+			break
+		}
+	}
+
+	if best.value == 0 {
+		return strconv.FormatUint(value, 10) + suffix
+	}
+
+	whole, frac := value/best.value, value%best.value
+	result := strconv.FormatUint(whole, 10)
+	if frac != 0 {
+		f := float64(frac) / float64(best.value)
+		result += strings.TrimPrefix(strconv.FormatFloat(f, 'f', 3, 64), "0")
+		result = strings.TrimRight(result, "0")
+	}
+
+	return result + best.suffix
+}
+
+// The defaultText returns the default value of the field for the help.
+// The default values of the ByteSize fields and of the integer fields
+// with the unit tag are shown in the human-readable units, like 10MiB
+// instead of 10485760.
+func (fc *fieldCast) defaultText() string {
+	def, unitName := fc.tagGroup.defaultValue(), fc.tagGroup.unit
+	if fc.item != nil && scalarType(fc.item.Type()) == byteSizeType {
+		unitName = unitBytes
+	}
+
+	if unitName == "" {
+		return def
+	}
+
+	items := []string{def}
+	if sep := fc.tagGroup.sepList; sep != "" {
+		items = strings.Split(def, sep)
+	}
+
+	for i, item := range items {
+		neg, r, err := parseUnits(item, unitName)
+		if err != nil {
+			return def
+		}
+
+		items[i] = formatUnits(r, unitName)
+		if neg && r != 0 {
+			items[i] = "-" + items[i]
+		}
+	}
+
+	return strings.Join(items, fc.tagGroup.sepList)
+}
@@ -0,0 +1,148 @@
+package opt
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParseUnits tests parseUnits function.
+func TestParseUnits(t *testing.T) {
+	tests := []struct {
+		value    string
+		unit     string
+		neg      bool
+		expected uint64
+		err      bool
+	}{
+		{"", unitBytes, false, 0, false},
+		{"512", unitBytes, false, 512, false},
+		{"512B", unitBytes, false, 512, false},
+		{"512K", unitBytes, false, 512 << 10, false},
+		{"512k", unitBytes, false, 512 << 10, false},
+		{"10MiB", unitBytes, false, 10 << 20, false},
+		{"10 mib", unitBytes, false, 10 << 20, false},
+		{"1.5GB", unitBytes, false, 1500000000, false},
+		{"1.5KiB", unitBytes, false, 1536, false},
+		{"2kb", unitBytes, false, 2000, false},
+		{"-1K", unitBytes, true, 1024, false},
+		{"15EiB", unitBytes, false, 15 << 60, false},
+		{"16EiB", unitBytes, false, 0, true},
+		{"20.5EB", unitBytes, false, 0, true},
+		{"10XB", unitBytes, false, 0, true},
+		{"K", unitBytes, false, 0, true},
+		{"1..5K", unitBytes, false, 0, true},
+		{"10k", unitSI, false, 10000, false},
+		{"10K", unitSI, false, 10000, false},
+		{"2.5M", unitSI, false, 2500000, false},
+		{"1G", unitSI, false, 1000000000, false},
+		{"1m", unitSI, false, 0, true},
+		{"1KB", unitSI, false, 0, true},
+	}
+
+	for i, test := range tests {
+		neg, r, err := parseUnits(test.value, test.unit)
+		switch {
+		case test.err && err == nil:
+			t.Errorf("%d test, expected an error for %s", i, test.value)
+		case !test.err && err != nil:
+			t.Errorf("%d test, %v", i, err)
+		case r != test.expected || neg != test.neg:
+			t.Errorf("%d test, expected %v %d but %v %d",
+				i, test.neg, test.expected, neg, r)
+		}
+	}
+}
+
+// TestFormatUnits tests formatUnits function.
+func TestFormatUnits(t *testing.T) {
+	tests := []struct {
+		value    uint64
+		unit     string
+		expected string
+	}{
+		{0, unitBytes, "0B"},
+		{999, unitBytes, "999B"},
+		{1024, unitBytes, "1KiB"},
+		{1536, unitBytes, "1.5KiB"},
+		{10 << 20, unitBytes, "10MiB"},
+		{1000000, unitBytes, "1MB"},
+		{1500000000, unitBytes, "1.5GB"},
+		{1 << 63, unitBytes, "8EiB"},
+		{1001, unitBytes, "1.001KB"},
+		{999, unitSI, "999"},
+		{1500, unitSI, "1.5k"},
+		{2000000, unitSI, "2M"},
+	}
+
+	for i, test := range tests {
+		if r := formatUnits(test.value, test.unit); r != test.expected {
+			t.Errorf("%d test, expected %s but %s", i, test.expected, r)
+		}
+	}
+}
+
+// TestByteSize tests ByteSize type and unit tag.
+func TestByteSize(t *testing.T) {
+	type data struct {
+		Cache  ByteSize   `opt:"c" def:"64MiB" help:"cache size"`
+		Buffer int32      `opt:"b" unit:"bytes" def:"4096" help:"buffer"`
+		Small  uint8      `opt:"s" unit:"bytes"`
+		Limit  int        `opt:"l" unit:"si" def:"1500" help:"rate limit"`
+		Sizes  []ByteSize `opt:"S" sep:","`
+		Help   string     `opt:"?"`
+	}
+
+	args := data{}
+	errs := unmarshalOpt(&args, []string{"./app", "-b", "1.5K", "-s", "200",
+		"-l-2k", "-S", "1K,2kb"})
+	if len(errs) != 0 {
+		t.Fatal(errs)
+	}
+
+	if args.Cache != 64<<20 || args.Buffer != 1536 || args.Small != 200 ||
+		args.Limit != -2000 {
+		t.Errorf("unexpected values %v", args)
+	}
+
+	if len(args.Sizes) != 2 || args.Sizes[0] != 1024 ||
+		args.Sizes[1] != 2000 {
+		t.Errorf("unexpected sizes %v", args.Sizes)
+	}
+
+	// The help shows the default values in the human units.
+	for _, s := range []string{"(default: 64MiB)", "(default: 4KiB)",
+		"(default: 1.5k)"} {
+		if !strings.Contains(args.Help, s) {
+			t.Errorf("expected %s in help:\n%s", s, args.Help)
+		}
+	}
+
+	// The overflow of the target kind.
+	for _, test := range [][]string{
+		{"./app", "-s", "1K"},
+		{"./app", "-b", "2GiB"},
+		{"./app", "-c-1K"},
+		{"./app", "-c", "1XB"},
+	} {
+		if errs := unmarshalOpt(&data{}, test); len(errs) != 1 {
+			t.Errorf("expected one error for %v but %v", test, errs)
+		}
+	}
+
+	for _, obj := range []interface{}{
+		&struct {
+			Name string `unit:"bytes"`
+		}{},
+		&struct {
+			Size int `unit:"bits"`
+		}{},
+	} {
+		if err := Check(obj); err == nil {
+			t.Errorf("expected an error for %T", obj)
+		}
+	}
+
+	if s := ByteSize(1536).String(); s != "1.5KiB" {
+		t.Errorf("expected 1.5KiB but %s", s)
+	}
+}
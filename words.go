@@ -0,0 +1,165 @@
+package opt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The word is an item of the string split by the shell-like rules.
+type word struct {
+	value string // value of the word without quotes
+	line  int    // number of the line where the word starts
+}
+
+// SplitError is an error of the splitting a string into arguments,
+// like unterminated quote, with the position of the problem.
+type SplitError struct {
+	Line   int    // number of the line, starts from 1
+	Column int    // number of the column in runes, starts from 1
+	Msg    string // error message
+}
+
+// Error returns the error message with position of the error.
+func (e *SplitError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// Split splits the str into arguments using POSIX shell-like rules
+// without any expansions (variables, globs, etc.), for example:
+//
+//	args, err := opt.Split(`--debug -p 8080 --name 'John Doe'`)
+//	// args: []string{"--debug", "-p", "8080", "--name", "John Doe"}
+//
+// The arguments are separated by spaces, tabs and newlines. The single
+// quotes keep the literal value of all characters between them. The double
+// quotes keep the literal value of all characters between them, except
+// backslash which escapes the ", \, $, ` characters and the newline.
+// The backslash outside the quotes escapes any next character. The #
+// character at the beginning of the argument starts a comment to the
+// end of the line.
+//
+// For unterminated quotes and the backslash at the end of the string
+// returns *SplitError with the position of the problem.
+func Split(str string) ([]string, error) {
+	words, err := splitWords(str)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0, len(words))
+	for _, w := range words {
+		result = append(result, w.value)
+	}
+
+	return result, nil
+}
+
+// The splitWords splits the str into words using POSIX shell-like rules
+// without any expansions:
+//
+//   - the words are separated by spaces, tabs and newlines;
+//   - the single quotes keep the literal value of all characters
+//     between them;
+//   - the double quotes keep the literal value of all characters between
+//     them, except backslash which escapes the ", \, $, ` characters
+//     and the newline;
+//   - the backslash outside the quotes escapes any next character,
+//     the backslash and newline is a line continuation;
+//   - the # character at the beginning of the word starts a comment
+//     to the end of the line.
+func splitWords(str string) ([]word, error) {
+	var (
+		result  []word
+		buf     strings.Builder
+		inWord  bool // true if the word is started, even if it's empty
+		quote   rune // current quote character, 0 if outside the quotes
+		escaped bool // true if the previous character is a backslash
+		comment bool // true if inside the comment
+
+		line, column   = 1, 0
+		qLine, qColumn int // position of the opening quote
+		start          int // line of the current word
+	)
+
+	for _, c := range str {
+		column++
+
+		switch {
+		case comment:
+			// Skip everything up to the end of the line.
+			if c == '\n' {
+				comment = false
+			}
+		case escaped:
+			escaped = false
+			switch {
+			case c == '\n':
+				// Line continuation, it doesn't start a word.
+			case !inWord:
+				inWord, start = true, line
+				buf.WriteRune(c)
+			case quote == '"' && !strings.ContainsRune("\"\\$`", c):
+				// Inside the double quotes the backslash
+				// escapes some characters only.
+				buf.WriteRune('\\')
+				buf.WriteRune(c)
+			default:
+				buf.WriteRune(c)
+			}
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			} else {
+				buf.WriteRune(c)
+			}
+		case quote == '"':
+			switch c {
+			case '"':
+				quote = 0
+			case '\\':
+				escaped = true
+			default:
+				buf.WriteRune(c)
+			}
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inWord {
+				result = append(result, word{buf.String(), start})
+				buf.Reset()
+				inWord = false
+			}
+		case c == '#' && !inWord:
+			comment = true
+		case c == '\\':
+			// The escaped character starts the word.
+			escaped = true
+		default:
+			if !inWord {
+				inWord, start = true, line
+			}
+
+			switch c {
+			case '\'', '"':
+				quote, qLine, qColumn = c, line, column
+			default:
+				buf.WriteRune(c)
+			}
+		}
+
+		if c == '\n' {
+			line, column = line+1, 0
+		}
+	}
+
+	switch {
+	case quote == '\'':
+		return nil, &SplitError{qLine, qColumn, "unterminated single quote"}
+	case quote == '"':
+		return nil, &SplitError{qLine, qColumn, "unterminated double quote"}
+	case escaped:
+		return nil, &SplitError{line, column, "unexpected end after backslash"}
+	case inWord:
+		result = append(result, word{buf.String(), start})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,91 @@
+package opt
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestSplitWords tests splitWords function.
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		str    string
+		values []string
+		lines  []int
+	}{
+		{"", nil, nil},
+		{"  -d  --port 80 ", []string{"-d", "--port", "80"}, []int{1, 1, 1}},
+		{"--name 'John Doe'", []string{"--name", "John Doe"}, []int{1, 1}},
+		{`--name="John \"JD\" Doe"`, []string{`--name=John "JD" Doe`}, nil},
+		{`"a\b" 'a\b' a\b`, []string{`a\b`, `a\b`, "ab"}, nil},
+		{`'' "" x`, []string{"", "", "x"}, nil},
+		{"a\\ b c", []string{"a b", "c"}, nil},
+		{"-d # comment\n-v#x", []string{"-d", "-v#x"}, []int{1, 2}},
+		{"one \\\ntwo\n'th\nree' four", []string{"one", "two", "th\nree",
+			"four"}, []int{1, 2, 3, 4}},
+	}
+
+	for i, test := range tests {
+		words, err := splitWords(test.str)
+		if err != nil {
+			t.Errorf("%d test, %v", i, err)
+			continue
+		}
+
+		var values []string
+		var lines []int
+		for _, w := range words {
+			values = append(values, w.value)
+			lines = append(lines, w.line)
+		}
+
+		if !reflect.DeepEqual(values, test.values) {
+			t.Errorf("%d test, expected %q but %q", i, test.values, values)
+		}
+
+		if test.lines != nil && !reflect.DeepEqual(lines, test.lines) {
+			t.Errorf("%d test, expected %v but %v", i, test.lines, lines)
+		}
+	}
+}
+
+// TestSplitWordsErrors tests splitWords function with errors.
+func TestSplitWordsErrors(t *testing.T) {
+	tests := []struct {
+		str string
+		err string
+	}{
+		{"--name 'John", "line 1, column 8: unterminated single quote"},
+		{"-d\n  --name \"John", "line 2, column 10: unterminated double quote"},
+		{"-d \\", "line 1, column 4: unexpected end after backslash"},
+	}
+
+	for i, test := range tests {
+		_, err := splitWords(test.str)
+		if err == nil || err.Error() != test.err {
+			t.Errorf("%d test, expected %q but %v", i, test.err, err)
+		}
+	}
+}
+
+// TestSplitLine tests Split function.
+func TestSplitLine(t *testing.T) {
+	r, err := Split(`--debug -p 8080 --name 'John Doe' ""`)
+	if err != nil {
+		t.Error(err)
+	}
+
+	exp := []string{"--debug", "-p", "8080", "--name", "John Doe", ""}
+	if !reflect.DeepEqual(r, exp) {
+		t.Errorf("expected %q but %q", exp, r)
+	}
+
+	_, err = Split("--name \"John Doe")
+	e, ok := err.(*SplitError)
+	if !ok {
+		t.Fatalf("expected *SplitError but %T", err)
+	}
+
+	if e.Line != 1 || e.Column != 8 {
+		t.Errorf("expected position 1:8 but %d:%d", e.Line, e.Column)
+	}
+}